@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/cache"
+	"interview-telkom-6/config"
+	"interview-telkom-6/handler"
+	"interview-telkom-6/metrics"
+	"interview-telkom-6/repository/migration"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/service"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// app holds the configuration and services shared by every subcommand.
+type app struct {
+	cfg            *config.Config
+	log            *slog.Logger
+	db             *sqlx.DB
+	discountSvc    *service.DiscountService
+	currencySvc    *service.CurrencyService
+	taxSvc         *service.TaxService
+	addressSvc     *service.AddressService
+	productSvc     *service.ProductService
+	cartSvc        service.CartService
+	paymentSvc     *service.PaymentService
+	returnSvc      *service.ReturnService
+	idempotencySvc *service.IdempotencyService
+}
+
+func newApp(ctx context.Context, db *sqlx.DB, cfg *config.Config, log *slog.Logger) *app {
+	productRepo := persistence.NewProductRepository(db, log, cfg.Database.QueryTimeout)
+	if cfg.Cache.Products {
+		productCache := cache.NewLRU(cfg.Cache.Size, cfg.Cache.TTL)
+		productRepo = persistence.NewCachedProductRepository(productRepo, productCache, log)
+	}
+	cartRepo := persistence.NewCartRepository(db, log, cfg.Database.QueryTimeout)
+	cartProductRepo := persistence.NewCartProductRepository(db, log, cfg.Database.QueryTimeout)
+	campaignRepo := persistence.NewDiscountCampaignRepository(db, log, cfg.Database.QueryTimeout)
+	campaignTargetRepo := persistence.NewDiscountCampaignTargetRepository(db, log, cfg.Database.QueryTimeout)
+	exchangeRateRepo := persistence.NewExchangeRateRepository(db, log, cfg.Database.QueryTimeout)
+	taxRuleRepo := persistence.NewTaxRuleRepository(db, log, cfg.Database.QueryTimeout)
+	addressRepo := persistence.NewAddressRepository(db, log, cfg.Database.QueryTimeout)
+	paymentRepo := persistence.NewPaymentRepository(db, log, cfg.Database.QueryTimeout)
+	refundRepo := persistence.NewRefundRepository(db, log, cfg.Database.QueryTimeout)
+	returnRepo := persistence.NewReturnRepository(db, log, cfg.Database.QueryTimeout)
+	fileRepo := persistence.NewFileRepository(db, log, cfg.Database.QueryTimeout)
+	idempotencyKeyRepo := persistence.NewIdempotencyKeyRepository(db, log, cfg.Database.QueryTimeout)
+	currencySvc := service.NewCurrencyService(exchangeRateRepo)
+	discountSvc := service.NewDiscountService(ctx, campaignRepo, campaignTargetRepo, currencySvc)
+	taxSvc := service.NewTaxService(taxRuleRepo)
+	addressSvc := service.NewAddressService(ctx, addressRepo)
+	invoiceSvc := service.NewInvoiceService(fileRepo, service.NewLocalFileStorage(cfg.Storage.Dir))
+	productSvc := service.NewProductService(ctx, productRepo, discountSvc, currencySvc)
+	cartSvc := service.NewCartService(
+		ctx, cartRepo, cartProductRepo, productRepo, discountSvc, currencySvc, taxSvc, addressSvc,
+		service.NewDefaultShippingCalculator(),
+	)
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentRepo, refundRepo, cartSvc, invoiceSvc,
+		service.NewFakePaymentProvider(cfg.Payment.WebhookSecret),
+	)
+	returnSvc := service.NewReturnService(ctx, paymentRepo, returnRepo, productRepo, paymentSvc)
+
+	return &app{
+		cfg: cfg, log: log, db: db, discountSvc: discountSvc, currencySvc: currencySvc, taxSvc: taxSvc, addressSvc: addressSvc,
+		productSvc: productSvc, cartSvc: cartSvc, paymentSvc: paymentSvc, returnSvc: returnSvc,
+		idempotencySvc: service.NewIdempotencyService(idempotencyKeyRepo, cfg.Idempotency.ClaimTimeout),
+	}
+}
+
+// serve runs the HTTP API until ctx is cancelled, then stops accepting connections and waits up to
+// ShutdownTimeout for in-flight requests before returning.
+func (a *app) serve(ctx context.Context, migrator *migration.Migrator) error {
+	if err := metrics.RegisterDB(a.db, a.cfg.Database.Name); err != nil {
+		return err
+	}
+
+	r := gin.New()
+	// lets services reach the request context, with the request id and span the logger adds to their records
+	r.ContextWithFallback = true
+	r.Use(handler.Tracing())
+	r.Use(handler.RequestLogger(a.log))
+	r.Use(handler.Metrics())
+	r.Use(gin.Recovery())
+	rGroup := r.Group("/api")
+	rGroup.Use(handler.Idempotency(a.idempotencySvc))
+
+	gin.SetMode(gin.ReleaseMode)
+
+	handler.NewProductHandler(rGroup, a.productSvc, a.cfg.HTTPCache.Products)
+	handler.NewCartHandler(rGroup, a.cartSvc)
+	handler.NewDiscountCampaignHandler(rGroup, a.discountSvc)
+	handler.NewExchangeRateHandler(rGroup, a.currencySvc)
+	handler.NewTaxRuleHandler(rGroup, a.taxSvc)
+	handler.NewAddressHandler(rGroup, a.addressSvc)
+	handler.NewPaymentHandler(rGroup, a.paymentSvc)
+	handler.NewReturnHandler(rGroup, a.returnSvc)
+
+	healthSvc := service.NewHealthService(a.db, migrator)
+	handler.NewHealthHandler(r, healthSvc)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", a.cfg.App.Port), Handler: r}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	a.log.Info("listening", "addr", srv.Addr)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	a.log.Info("shutting down, waiting for in-flight requests", "timeout", a.cfg.App.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.App.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	return nil
+}
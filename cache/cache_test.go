@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.TODO()
+	c := NewLRU(2, time.Minute)
+
+	c.Set(ctx, "a", []byte("1"))
+	c.Set(ctx, "b", []byte("2"))
+	_, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	// b is the least recently used now
+	c.Set(ctx, "c", []byte("3"))
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get(ctx, "b")
+	assert.False(t, ok)
+
+	c.Set(ctx, "a", []byte("4"))
+	value, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("4"), value)
+	value, ok = c.Get(ctx, "c")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), value)
+}
+
+func TestLRUExpires(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	c := NewLRU(10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"))
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	_, ok = c.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+
+	// setting again restarts the ttl
+	c.Set(ctx, "a", []byte("2"))
+	now = now.Add(30 * time.Second)
+	value, ok := c.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("2"), value)
+}
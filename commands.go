@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"flag"
+	"fmt"
+	"interview-telkom-6/request"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed fixtures/products.jsonl
+var productFixtures []byte
+
+// seed imports the fixture products, or the -file catalogue, updating the products already there so it can
+// be run again.
+func (a *app) seed(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := flags.String("file", "", "CSV or JSON Lines catalogue, the embedded fixtures by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var body io.Reader = bytes.NewReader(productFixtures)
+	req := request.ProductImportRequest{Format: request.ImportFormatJSONL, Upsert: true}
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		body = f
+		if strings.HasSuffix(strings.ToLower(*file), ".csv") {
+			req.Format = request.ImportFormatCSV
+		}
+	}
+
+	res, err := a.productSvc.Import(ctx, &req, body)
+	if err != nil {
+		return err
+	}
+	for _, val := range res.Errors {
+		slog.WarnContext(ctx, "rejected row", "row", val.Row, "name", val.Name, "error", val.Message)
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("%d of %d rows rejected, nothing imported", len(res.Errors), res.Total)
+	}
+
+	slog.InfoContext(ctx, "seeded products", "total", res.Total, "created", res.Created, "updated", res.Updated)
+	return nil
+}
+
+// purgeCarts deletes the carts created more than -older-than ago.
+func (a *app) purgeCarts(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("purge-carts", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 0, "age of the carts to delete, e.g. 720h")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	deleted, err := a.cartSvc.Purge(ctx, *olderThan)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "purged carts", "deleted", deleted)
+	return nil
+}
+
+// purgeIdempotencyKeys deletes the idempotency keys recorded more than -older-than ago.
+func (a *app) purgeIdempotencyKeys(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("purge-idempotency-keys", flag.ContinueOnError)
+	olderThan := flags.Duration("older-than", 24*time.Hour, "age of the keys to delete")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	deleted, err := a.idempotencySvc.Purge(ctx, *olderThan)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "purged idempotency keys", "deleted", deleted)
+	return nil
+}
+
+// export writes the catalogue like GET /api/products/export, `export products` being the only export so far.
+func (a *app) export(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "products" {
+		return fmt.Errorf("usage: export products [-format csv|jsonl|xlsx] [-search name] [-currency code] [-o file]")
+	}
+
+	flags := flag.NewFlagSet("export products", flag.ContinueOnError)
+	format := flags.String("format", request.ImportFormatCSV, "csv, jsonl or xlsx")
+	search := flags.String("search", "", "only export the products whose name contains search")
+	currency := flags.String("currency", "", "convert prices to currency")
+	output := flags.String("o", "", "file to write, stdout by default")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	req := request.ProductExportRequest{Format: strings.ToLower(*format)}
+	req.Search = *search
+	req.Currency = *currency
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	return a.productSvc.Export(ctx, &req, w)
+}
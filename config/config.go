@@ -0,0 +1,308 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the whole application configuration. Every field is read, by increasing precedence, from its
+// `default` tag, the YAML file, then the environment variable named by its `env` tag, .env being loaded
+// into the environment without overriding it. Fields tagged `required` can't be left empty.
+type Config struct {
+	App         AppConfig         `yaml:"app"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Payment     PaymentConfig     `yaml:"payment"`
+	Log         LogConfig         `yaml:"log"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Cache       CacheConfig       `yaml:"cache"`
+	HTTPCache   HTTPCacheConfig   `yaml:"http_cache"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Features    FeatureFlags      `yaml:"features"`
+}
+
+type AppConfig struct {
+	Port     int    `yaml:"port" env:"APP_PORT" default:"8000"`
+	Timezone string `yaml:"timezone" env:"APP_TIMEZONE" default:"Asia/Jakarta"`
+	// ShutdownTimeout is how long in-flight requests get to finish once a stop signal is received.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" default:"15s"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" env:"DATABASE_HOST" required:"true"`
+	Port            int           `yaml:"port" env:"DATABASE_PORT" default:"5432"`
+	User            string        `yaml:"user" env:"DATABASE_USER" required:"true"`
+	Password        string        `yaml:"password" env:"DATABASE_PASSWORD"`
+	Name            string        `yaml:"name" env:"DATABASE_NAME" required:"true"`
+	SSLMode         string        `yaml:"ssl_mode" env:"DATABASE_SSL_MODE" default:"disable"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS" default:"5"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME" default:"30m"`
+	// QueryTimeout cancels the repository queries running longer, zero disabling it.
+	QueryTimeout time.Duration `yaml:"query_timeout" env:"DATABASE_QUERY_TIMEOUT" default:"5s"`
+	// ConnectRetries and ConnectBackoff bound the startup ping, the backoff doubling after each attempt.
+	ConnectRetries int           `yaml:"connect_retries" env:"DATABASE_CONNECT_RETRIES" default:"5"`
+	ConnectBackoff time.Duration `yaml:"connect_backoff" env:"DATABASE_CONNECT_BACKOFF" default:"1s"`
+}
+
+type StorageConfig struct {
+	Dir string `yaml:"dir" env:"FILE_STORAGE_DIR" default:"storage"`
+}
+
+type PaymentConfig struct {
+	WebhookSecret string `yaml:"webhook_secret" env:"PAYMENT_WEBHOOK_SECRET" required:"true"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error, SQL queries being logged at debug.
+	Level  string `yaml:"level" env:"LOG_LEVEL" default:"info"`
+	Format string `yaml:"format" env:"LOG_FORMAT" default:"json"`
+}
+
+type TracingConfig struct {
+	// Exporter is otlp, stdout, which prints the spans for local runs, or none.
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	// Endpoint is the URL of the OTLP/HTTP collector, the OTEL_EXPORTER_OTLP_* variables being used when empty.
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" default:"shop"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+type CacheConfig struct {
+	// Products serves product reads from an in-memory cache of Size entries kept for TTL at most, emptied by
+	// product writes. Other instances see the writes of this one once their entries expire.
+	Products bool          `yaml:"products" env:"CACHE_PRODUCTS" default:"true"`
+	Size     int           `yaml:"size" env:"CACHE_SIZE" default:"10000"`
+	TTL      time.Duration `yaml:"ttl" env:"CACHE_TTL" default:"1m"`
+}
+
+// HTTPCacheConfig holds the Cache-Control header of the routes answering conditional GETs, none being sent
+// when empty.
+type HTTPCacheConfig struct {
+	// Products is the one of GET /api/products.
+	Products string `yaml:"products" env:"HTTP_CACHE_PRODUCTS" default:"public, max-age=60"`
+}
+
+type IdempotencyConfig struct {
+	// ClaimTimeout is how long a request holds its Idempotency-Key, a retry claiming the key again once it
+	// expires without a recorded response, as when the instance serving the request crashed.
+	ClaimTimeout time.Duration `yaml:"claim_timeout" env:"IDEMPOTENCY_CLAIM_TIMEOUT" default:"1m"`
+}
+
+// FeatureFlags switch optional behaviours on or off.
+type FeatureFlags struct {
+	// AutoMigrate applies the pending migrations when the server starts.
+	AutoMigrate bool `yaml:"auto_migrate" env:"FEATURE_AUTO_MIGRATE" default:"true"`
+}
+
+var (
+	sslModes   = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"json", "text"}
+	exporters  = []string{"otlp", "stdout", "none"}
+)
+
+// Load reads the configuration from file, skipped when empty or missing, .env and the environment, then
+// validates it. The returned error lists every invalid field at once.
+func Load(file string) (res *Config, err error) {
+	res = new(Config)
+	if err := walk(reflect.ValueOf(res).Elem(), "", setDefault); err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, res); err != nil {
+			return nil, fmt.Errorf("config %s: %w", file, err)
+		}
+	}
+
+	// .env is optional, variables already set win over it
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("config .env: %w", err)
+	}
+	if err := walk(reflect.ValueOf(res).Elem(), "", setFromEnv); err != nil {
+		return nil, err
+	}
+
+	return res, res.Validate()
+}
+
+// Validate checks the required fields are set and the values are usable.
+func (c *Config) Validate() error {
+	problems := make([]string, 0)
+	_ = walk(
+		reflect.ValueOf(c).Elem(), "", func(field reflect.StructField, value reflect.Value, path string) error {
+			if field.Tag.Get("required") == "true" && value.IsZero() {
+				problems = append(problems, fmt.Sprintf("%s (%s) is required", field.Tag.Get("env"), path))
+			}
+			return nil
+		},
+	)
+
+	if c.App.Port <= 0 || c.App.Port > 65535 {
+		problems = append(problems, "APP_PORT (app.port) must be between 1 and 65535")
+	}
+	if _, err := time.LoadLocation(c.App.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("APP_TIMEZONE (app.timezone) %q is unknown", c.App.Timezone))
+	}
+	if !contains(sslModes, c.Database.SSLMode) {
+		problems = append(
+			problems, "DATABASE_SSL_MODE (database.ssl_mode) must be one of "+strings.Join(sslModes, ", "),
+		)
+	}
+	if !contains(logLevels, strings.ToLower(c.Log.Level)) {
+		problems = append(problems, "LOG_LEVEL (log.level) must be one of "+strings.Join(logLevels, ", "))
+	}
+	if !contains(logFormats, strings.ToLower(c.Log.Format)) {
+		problems = append(problems, "LOG_FORMAT (log.format) must be one of "+strings.Join(logFormats, ", "))
+	}
+	if !contains(exporters, c.Tracing.Exporter) {
+		problems = append(
+			problems, "TRACING_EXPORTER (tracing.exporter) must be one of "+strings.Join(exporters, ", "),
+		)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "TRACING_SAMPLE_RATIO (tracing.sample_ratio) must be between 0 and 1")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		problems = append(problems, "DATABASE_MAX_OPEN_CONNS and DATABASE_MAX_IDLE_CONNS can't be negative")
+	}
+	if c.Database.QueryTimeout < 0 {
+		problems = append(problems, "DATABASE_QUERY_TIMEOUT (database.query_timeout) can't be negative")
+	}
+	if c.Cache.Products && (c.Cache.Size <= 0 || c.Cache.TTL <= 0) {
+		problems = append(problems, "CACHE_SIZE (cache.size) and CACHE_TTL (cache.ttl) must be positive")
+	}
+	if c.Idempotency.ClaimTimeout <= 0 {
+		problems = append(problems, "IDEMPOTENCY_CLAIM_TIMEOUT (idempotency.claim_timeout) must be positive")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Location returns the time zone of the application, validated by Load.
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.App.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// DSN returns the lib/pq connection string of the database.
+func (c DatabaseConfig) DSN(timezone string) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s", quote(c.Host), quote(c.User),
+		quote(c.Password), quote(c.Name), c.Port, c.SSLMode, quote(timezone),
+	)
+}
+
+// quote escapes a connection string value, quoting it when it is empty or has spaces.
+func quote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	if value == "" || strings.ContainsAny(value, " \t") {
+		return "'" + value + "'"
+	}
+
+	return value
+}
+
+// walk calls fn on every leaf field of v, path being its dotted YAML name.
+func walk(
+	v reflect.Value, prefix string, fn func(field reflect.StructField, value reflect.Value, path string) error,
+) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		path := strings.TrimPrefix(prefix+"."+field.Tag.Get("yaml"), ".")
+		if field.Type.Kind() == reflect.Struct {
+			if err := walk(v.Field(i), path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(field, v.Field(i), path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func setDefault(field reflect.StructField, value reflect.Value, path string) error {
+	if raw, ok := field.Tag.Lookup("default"); ok {
+		return set(value, raw, path)
+	}
+
+	return nil
+}
+
+func setFromEnv(field reflect.StructField, value reflect.Value, path string) error {
+	if raw, ok := os.LookupEnv(field.Tag.Get("env")); ok && raw != "" {
+		return set(value, raw, field.Tag.Get("env"))
+	}
+
+	return nil
+}
+
+func set(value reflect.Value, raw string, name string) error {
+	switch value.Interface().(type) {
+	case string:
+		value.SetString(raw)
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("config %s: %q isn't a duration", name, raw)
+		}
+		value.SetInt(int64(d))
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("config %s: %q isn't a number", name, raw)
+		}
+		value.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("config %s: %q isn't a number", name, raw)
+		}
+		value.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("config %s: %q isn't a boolean", name, raw)
+		}
+		value.SetBool(b)
+	default:
+		return fmt.Errorf("config %s: unsupported type %s", name, value.Type())
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, val := range values {
+		if val == value {
+			return true
+		}
+	}
+
+	return false
+}
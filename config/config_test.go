@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("DATABASE_HOST", "localhost")
+	t.Setenv("DATABASE_USER", "postgres")
+	t.Setenv("DATABASE_NAME", "interview-telkom")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "secret")
+}
+
+func TestLoadDefaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, 8000, cfg.App.Port)
+	assert.Equal(t, "Asia/Jakarta", cfg.App.Timezone)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "disable", cfg.Database.SSLMode)
+	assert.Equal(t, 30*time.Minute, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 5*time.Second, cfg.Database.QueryTimeout)
+	assert.True(t, cfg.Features.AutoMigrate)
+	assert.Equal(t, "none", cfg.Tracing.Exporter)
+	assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	assert.True(t, cfg.Cache.Products)
+	assert.Equal(t, time.Minute, cfg.Cache.TTL)
+	assert.Equal(t, "public, max-age=60", cfg.HTTPCache.Products)
+	assert.Equal(t, time.Minute, cfg.Idempotency.ClaimTimeout)
+}
+
+func TestLoadYAMLOverriddenByEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("APP_PORT", "9000")
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	data := "app:\n  port: 8080\n  timezone: UTC\ndatabase:\n  max_open_conns: 50\n  conn_max_lifetime: 1h\n" +
+		"features:\n  auto_migrate: false\n"
+	assert.NoError(t, os.WriteFile(file, []byte(data), 0o600))
+
+	cfg, err := config.Load(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 9000, cfg.App.Port)
+	assert.Equal(t, "UTC", cfg.App.Timezone)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, time.Hour, cfg.Database.ConnMaxLifetime)
+	assert.False(t, cfg.Features.AutoMigrate)
+}
+
+func TestLoadMissingRequired(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("DATABASE_HOST", "")
+	t.Setenv("PAYMENT_WEBHOOK_SECRET", "")
+
+	_, err := config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DATABASE_HOST (database.host) is required")
+	assert.Contains(t, err.Error(), "PAYMENT_WEBHOOK_SECRET (payment.webhook_secret) is required")
+}
+
+func TestLoadInvalidValues(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("DATABASE_SSL_MODE", "sometimes")
+	t.Setenv("APP_TIMEZONE", "Mars/Olympus")
+	t.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+	t.Setenv("CACHE_SIZE", "-1")
+	t.Setenv("IDEMPOTENCY_CLAIM_TIMEOUT", "0s")
+
+	_, err := config.Load("")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DATABASE_SSL_MODE")
+	assert.Contains(t, err.Error(), "APP_TIMEZONE")
+	assert.Contains(t, err.Error(), "TRACING_SAMPLE_RATIO")
+	assert.Contains(t, err.Error(), "CACHE_SIZE")
+	assert.Contains(t, err.Error(), "IDEMPOTENCY_CLAIM_TIMEOUT")
+
+	t.Setenv("DATABASE_PORT", "five")
+	_, err = config.Load("")
+	assert.EqualError(t, err, `config DATABASE_PORT: "five" isn't a number`)
+}
+
+func TestDSN(t *testing.T) {
+	db := config.DatabaseConfig{
+		Host: "localhost", Port: 5432, User: "postgres", Password: "p@ss word", Name: "shop", SSLMode: "require",
+	}
+
+	assert.Equal(
+		t, "host=localhost user=postgres password='p@ss word' dbname=shop port=5432 sslmode=require TimeZone=UTC",
+		db.DSN("UTC"),
+	)
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "github.com/google/uuid"
+
+// Address is a shipping address of the customer of the carts with the same FullName.
+// Country is an ISO 3166-1 alpha-2 code.
+type Address struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	FullName   string    `json:"full_name" db:"full_name"`
+	Label      string    `json:"label" db:"label"`
+	Recipient  string    `json:"recipient" db:"recipient"`
+	Phone      string    `json:"phone" db:"phone"`
+	Street     string    `json:"street" db:"street"`
+	City       string    `json:"city" db:"city"`
+	Province   string    `json:"province" db:"province"`
+	PostalCode string    `json:"postal_code" db:"postal_code"`
+	Country    string    `json:"country" db:"country"`
+	IsDefault  bool      `json:"is_default" db:"is_default"`
+}
+
+func (e *Address) GenerateUUID() {
+	e.ID = uuid.New()
+}
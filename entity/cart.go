@@ -1,10 +1,16 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Cart struct {
-	ID       uuid.UUID `json:"id" db:"id"`
-	FullName string    `json:"full_name" db:"full_name"`
+	ID        uuid.UUID `json:"id" db:"id"`
+	FullName  string    `json:"full_name" db:"full_name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Version   int64     `json:"version" db:"version"`
 }
 
 func (e *Cart) GenerateUUID() {
@@ -6,4 +6,5 @@ type CartProduct struct {
 	CartID    uuid.UUID `json:"cart_id" db:"cart_id"`
 	ProductID uuid.UUID `json:"product_id" db:"product_id"`
 	Quantity  int       `json:"quantity" db:"quantity"`
+	Version   int64     `json:"version" db:"version"`
 }
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// DiscountCampaign discounts its targets by Value, a percentage or a fixed amount in Currency.
+type DiscountCampaign struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name"`
+	DiscountType string     `json:"discount_type" db:"discount_type"`
+	Value        util.Money `json:"value" db:"value"`
+	Currency     string     `json:"currency" db:"currency"`
+	Priority     int        `json:"priority" db:"priority"`
+	IsStackable  bool       `json:"is_stackable" db:"is_stackable"`
+	StartDate    time.Time  `json:"start_date" db:"start_date"`
+	EndDate      time.Time  `json:"end_date" db:"end_date"`
+}
+
+func (e *DiscountCampaign) GenerateUUID() {
+	e.ID = uuid.New()
+}
+
+// IsActive reports whether the campaign runs on the given day, both start and end date inclusive.
+func (e *DiscountCampaign) IsActive(at time.Time) bool {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(e.StartDate.Year(), e.StartDate.Month(), e.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(e.EndDate.Year(), e.EndDate.Month(), e.EndDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	return !day.Before(start) && !day.After(end)
+}
+
+// Amount returns the discount the campaign gives on price, never more than price itself.
+// Percentages are rounded with util.Money rules, a fixed Value must be in the currency of price.
+func (e *DiscountCampaign) Amount(price util.Money) util.Money {
+	var amount util.Money
+	switch e.DiscountType {
+	case DiscountTypePercentage:
+		amount = price.Percent(e.Value)
+	case DiscountTypeFixed:
+		amount = e.Value
+	}
+
+	if amount.IsNegative() {
+		return util.Money{}
+	}
+
+	return amount.Min(price)
+}
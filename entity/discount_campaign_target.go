@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// DiscountCampaignTarget links a campaign to either a single product or a whole category.
+type DiscountCampaignTarget struct {
+	CampaignID uuid.UUID      `json:"campaign_id" db:"campaign_id"`
+	ProductID  uuid.NullUUID  `json:"product_id" db:"product_id"`
+	Category   sql.NullString `json:"category" db:"category"`
+}
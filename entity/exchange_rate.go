@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"interview-telkom-6/util"
+	"time"
+)
+
+// ExchangeRate converts one BaseCurrency into Rate units of QuoteCurrency.
+type ExchangeRate struct {
+	BaseCurrency  string    `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency" db:"quote_currency"`
+	Rate          util.Rate `json:"rate" db:"rate"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "github.com/google/uuid"
+
+// File is a stored file, Location is its path within BucketName.
+type File struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Location   string    `json:"location" db:"location"`
+	BucketName string    `json:"bucket_name" db:"bucket_name"`
+}
+
+func (e *File) GenerateUUID() {
+	e.ID = uuid.New()
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IdempotencyKey is a request sent with an Idempotency-Key header, along with its response once served.
+// RequestHash tells whether a retry is the same request. Status is null while the request is being served.
+type IdempotencyKey struct {
+	Key         string         `json:"key" db:"key"`
+	RequestHash string         `json:"request_hash" db:"request_hash"`
+	Status      sql.NullInt64  `json:"status" db:"status"`
+	ContentType sql.NullString `json:"content_type" db:"content_type"`
+	Body        []byte         `json:"body" db:"body"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,159 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusFailed   = "failed"
+	PaymentStatusRefunded = "refunded"
+)
+
+// Payment is a charge taken through Provider for the total of a cart, ProviderRef is the id of the charge
+// at the provider. Snapshot is the cart as it was charged, nil for payments created before snapshots were
+// kept.
+type Payment struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	CartID      uuid.UUID        `json:"cart_id" db:"cart_id"`
+	Provider    string           `json:"provider" db:"provider"`
+	ProviderRef string           `json:"provider_ref" db:"provider_ref"`
+	Amount      util.Money       `json:"amount" db:"amount"`
+	Currency    string           `json:"currency" db:"currency"`
+	Status      string           `json:"status" db:"status"`
+	Snapshot    *PaymentSnapshot `json:"snapshot" db:"snapshot"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+func (e *Payment) GenerateUUID() {
+	e.ID = uuid.New()
+}
+
+// PaymentSnapshot is the priced cart a payment charges, kept as JSON with the payment so that later changes
+// to the cart, its products or their discounts don't change what was paid for.
+type PaymentSnapshot struct {
+	FullName      string           `json:"full_name"`
+	Currency      string           `json:"currency"`
+	Items         []PaymentItem    `json:"items"`
+	SubTotal      util.Money       `json:"sub_total"`
+	TotalDiscount util.Money       `json:"total_discount"`
+	Taxes         []PaymentTax     `json:"taxes"`
+	TotalTax      util.Money       `json:"total_tax"`
+	Shipping      *PaymentShipping `json:"shipping"`
+	Total         util.Money       `json:"total"`
+}
+
+// PaymentItem is a line of a PaymentSnapshot, amounts are per unit.
+type PaymentItem struct {
+	ProductID      uuid.UUID  `json:"product_id"`
+	Name           string     `json:"name"`
+	Quantity       int        `json:"quantity"`
+	Price          util.Money `json:"price"`
+	DiscountAmount util.Money `json:"discount_amount"`
+	FinalPrice     util.Money `json:"final_price"`
+}
+
+type PaymentTax struct {
+	Name        string     `json:"name"`
+	Rate        util.Money `json:"rate"`
+	IsInclusive bool       `json:"is_inclusive"`
+	Amount      util.Money `json:"amount"`
+}
+
+type PaymentShipping struct {
+	Service string     `json:"service"`
+	Name    string     `json:"name"`
+	Cost    util.Money `json:"cost"`
+}
+
+// ItemQuantity is a quantity of one of the items of a PaymentSnapshot.
+type ItemQuantity struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
+
+// ItemQuantities is stored as a JSON array.
+type ItemQuantities []ItemQuantity
+
+func (q ItemQuantities) Value() (driver.Value, error) {
+	if q == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]ItemQuantity(q))
+}
+
+func (q *ItemQuantities) Scan(value interface{}) error {
+	data, err := jsonBytes("item quantities", value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, (*[]ItemQuantity)(q))
+}
+
+// Item returns the line of the product productID.
+func (s *PaymentSnapshot) Item(productID uuid.UUID) (res PaymentItem, ok bool) {
+	for _, item := range s.Items {
+		if item.ProductID == productID {
+			return item, true
+		}
+	}
+
+	return res, false
+}
+
+func (s PaymentSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan decodes the snapshot, its amounts being formatted in its currency again.
+func (s *PaymentSnapshot) Scan(value interface{}) error {
+	data, err := jsonBytes("payment snapshot", value)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return err
+	}
+
+	for i := range s.Items {
+		item := &s.Items[i]
+		item.Price = item.Price.RoundTo(s.Currency)
+		item.DiscountAmount = item.DiscountAmount.RoundTo(s.Currency)
+		item.FinalPrice = item.FinalPrice.RoundTo(s.Currency)
+	}
+	for i := range s.Taxes {
+		s.Taxes[i].Amount = s.Taxes[i].Amount.RoundTo(s.Currency)
+	}
+	if s.Shipping != nil {
+		s.Shipping.Cost = s.Shipping.Cost.RoundTo(s.Currency)
+	}
+	s.SubTotal = s.SubTotal.RoundTo(s.Currency)
+	s.TotalDiscount = s.TotalDiscount.RoundTo(s.Currency)
+	s.TotalTax = s.TotalTax.RoundTo(s.Currency)
+	s.Total = s.Total.RoundTo(s.Currency)
+
+	return nil
+}
+
+// jsonBytes returns the JSON of a column scanned into name.
+func jsonBytes(name string, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported type %T", name, value)
+	}
+}
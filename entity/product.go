@@ -2,21 +2,37 @@ package entity
 
 import (
 	"database/sql"
+	"interview-telkom-6/util"
 
 	"github.com/google/uuid"
 )
 
 type Product struct {
-	ID                uuid.UUID       `json:"id" db:"id"`
-	Name              string          `json:"name" db:"name"`
-	Price             float64         `json:"price" db:"price"`
-	Description       string          `json:"description" db:"description"`
-	IsDiscount        bool            `json:"is_discount" db:"is_discount"`
-	DiscountValue     sql.NullFloat64 `json:"discount_value" db:"discount_value"`
-	StartDateDiscount sql.NullTime    `json:"start_date_discount" db:"start_date_discount"`
-	EndDateDiscount   sql.NullTime    `json:"end_date_discount" db:"end_date_discount"`
+	ID                uuid.UUID      `json:"id" db:"id"`
+	Name              string         `json:"name" db:"name"`
+	Price             util.Money     `json:"price" db:"price"`
+	Currency          string         `json:"currency" db:"currency"`
+	Description       string         `json:"description" db:"description"`
+	Weight            int            `json:"weight" db:"weight"`
+	Category          sql.NullString `json:"category" db:"category"`
+	IsDiscount        bool           `json:"is_discount" db:"is_discount"`
+	DiscountType      sql.NullString `json:"discount_type" db:"discount_type"`
+	DiscountValue     util.NullMoney `json:"discount_value" db:"discount_value"`
+	StartDateDiscount sql.NullTime   `json:"start_date_discount" db:"start_date_discount"`
+	EndDateDiscount   sql.NullTime   `json:"end_date_discount" db:"end_date_discount"`
+	Stock             int            `json:"stock" db:"stock"`
+	Version           int64          `json:"version" db:"version"`
 }
 
 func (e *Product) GenerateUUID() {
 	e.ID = uuid.New()
 }
+
+// PriceAfter returns the price once discount is taken off, never going below zero.
+func (e *Product) PriceAfter(discount util.Money) util.Money {
+	if !discount.LessThan(e.Price) {
+		return util.Money{}
+	}
+
+	return e.Price.Sub(discount)
+}
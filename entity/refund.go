@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RefundStatusPending   = "pending"
+	RefundStatusSucceeded = "succeeded"
+	RefundStatusFailed    = "failed"
+)
+
+// Refund is an amount given back on a payment, ProviderRef is the id of the refund at the payment provider.
+// A refund is pending until the provider answered, and counts as given back unless it failed. Items are the
+// ones it gives back the price of, ReturnID the return it pays for, if any.
+type Refund struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	PaymentID   uuid.UUID      `json:"payment_id" db:"payment_id"`
+	ProviderRef string         `json:"provider_ref" db:"provider_ref"`
+	Amount      util.Money     `json:"amount" db:"amount"`
+	Reason      string         `json:"reason" db:"reason"`
+	Status      string         `json:"status" db:"status"`
+	Items       ItemQuantities `json:"items" db:"items"`
+	ReturnID    uuid.NullUUID  `json:"return_id" db:"return_id"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+func (e *Refund) GenerateUUID() {
+	e.ID = uuid.New()
+}
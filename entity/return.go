@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+	ReturnStatusRejected  = "rejected"
+)
+
+// Return is a request to send Items of a payment back. It goes from requested to approved, to received once
+// the items are back in stock, then to refunded. It can be rejected until it is received.
+type Return struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	PaymentID uuid.UUID      `json:"payment_id" db:"payment_id"`
+	Status    string         `json:"status" db:"status"`
+	Reason    string         `json:"reason" db:"reason"`
+	Items     ItemQuantities `json:"items" db:"items"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+func (e *Return) GenerateUUID() {
+	e.ID = uuid.New()
+}
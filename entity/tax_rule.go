@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"database/sql"
+	"interview-telkom-6/util"
+
+	"github.com/google/uuid"
+)
+
+// TaxRule is the tax rate applied to products of Category, a rule without category applies to every other
+// product. Prices of an inclusive rule already contain the tax.
+type TaxRule struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	Name        string         `json:"name" db:"name"`
+	Category    sql.NullString `json:"category" db:"category"`
+	Rate        util.Money     `json:"rate" db:"rate"`
+	IsInclusive bool           `json:"is_inclusive" db:"is_inclusive"`
+}
+
+func (e *TaxRule) GenerateUUID() {
+	e.ID = uuid.New()
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"fmt"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type addressHandler struct {
+	addressSvc *service.AddressService
+}
+
+func NewAddressHandler(router *gin.RouterGroup, addressSvc *service.AddressService) {
+	h := addressHandler{addressSvc: addressSvc}
+
+	path := "/addresses"
+	router.POST(path, h.Store)
+	router.GET(path, h.Find)
+	router.PUT(fmt.Sprintf("%s/:id", path), h.Update)
+	router.DELETE(fmt.Sprintf("%s/:id", path), h.Delete)
+}
+
+func (h *addressHandler) Find(c *gin.Context) {
+	res, err := h.addressSvc.Find(c, c.Query("full_name"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success get data", Data: res})
+	return
+}
+
+func (h *addressHandler) Store(c *gin.Context) {
+	req := new(request.AddressAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	res, err := h.addressSvc.Store(c, req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *addressHandler) Update(c *gin.Context) {
+	req := new(request.AddressUpdateRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	res, err := h.addressSvc.Update(c, c.Param("id"), req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success updated data", Data: res})
+	return
+}
+
+func (h *addressHandler) Delete(c *gin.Context) {
+	err := h.addressSvc.Delete(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success delete data"})
+	return
+}
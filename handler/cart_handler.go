@@ -6,31 +6,32 @@ import (
 	"interview-telkom-6/response"
 	"interview-telkom-6/service"
 	"interview-telkom-6/util"
-	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 )
 
 type cartHandler struct {
-	cartService *service.CartService
+	cartService service.CartService
 }
 
-func NewCartHandler(router *gin.RouterGroup, cartService *service.CartService) {
+func NewCartHandler(router *gin.RouterGroup, cartService service.CartService) {
 	h := cartHandler{cartService: cartService}
 
 	path := "/carts"
 	router.POST(path, h.Store)
 	router.GET(path, h.Find)
+	router.GET(fmt.Sprintf("%s/shipping-options", path), h.ShippingOptions)
 	router.DELETE(fmt.Sprintf("%s/:product_id", path), h.DeleteProduct)
+	router.GET("/admin/carts/export", h.Export)
 }
 
 func (h *cartHandler) DeleteProduct(c *gin.Context) {
 	productID := c.Param("product_id")
 	err := h.cartService.DeleteProduct(c, productID)
 	if err != nil {
-		log.Println(err)
 		util.BuildErrorAPI(c, err)
 		return
 	}
@@ -38,15 +39,68 @@ func (h *cartHandler) DeleteProduct(c *gin.Context) {
 	return
 }
 
+// Export streams every cart with its items and totals, the format query parameter picks csv or jsonl and
+// from/to restrict the creation dates. Once streaming started an error can only cut the download short.
+func (h *cartHandler) Export(c *gin.Context) {
+	req := new(request.CartExportRequest)
+	req.From = c.Query("from")
+	req.To = c.Query("to")
+	req.Currency = c.Query("currency")
+	req.Format = strings.ToLower(c.DefaultQuery("format", request.ImportFormatJSONL))
+
+	contentType, ok := service.ExportContentType(req.Format)
+	if !ok || req.Format == request.ExportFormatXLSX {
+		util.BuildErrorAPI(
+			c, util.NewValidationError([]util.FieldError{{Field: "format", Message: "must be csv or jsonl"}}),
+		)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "carts."+req.Format))
+	c.Status(http.StatusOK)
+
+	if err := h.cartService.Export(c, req, c.Writer); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		util.BuildErrorAPI(c, err)
+		return
+	}
+}
+
 func (h *cartHandler) Find(c *gin.Context) {
 	req := new(request.CartCriteria)
 	req.FullName = c.Query("full_name")
 	req.ProductName = c.Query("product_name")
 	req.Quantity = c.Query("quantity")
+	req.Currency = c.Query("currency")
+	req.AddressID = c.Query("address_id")
+	req.ShippingService = c.Query("shipping_service")
 
 	res, err := h.cartService.Find(c, req)
 	if err != nil {
-		log.Println(err)
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success get data", Data: res})
+	return
+}
+
+func (h *cartHandler) ShippingOptions(c *gin.Context) {
+	req := new(request.CartCriteria)
+	req.FullName = c.Query("full_name")
+	req.Currency = c.Query("currency")
+	req.AddressID = c.Query("address_id")
+
+	res, err := h.cartService.ShippingOptions(c, req)
+	if err != nil {
 		util.BuildErrorAPI(c, err)
 		return
 	}
@@ -58,8 +112,7 @@ func (h *cartHandler) Find(c *gin.Context) {
 func (h *cartHandler) Store(c *gin.Context) {
 	req := new(request.CartAddRequest)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Println(err)
-
+		_ = c.Error(err)
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest, response.ErrorResponse{
 				Message: "StatusBadRequest",
@@ -84,7 +137,6 @@ func (h *cartHandler) Store(c *gin.Context) {
 
 	res, err := h.cartService.Store(c, req)
 	if err != nil {
-		log.Println(err)
 		util.BuildErrorAPI(c, err)
 		return
 	}
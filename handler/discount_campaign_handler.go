@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type discountCampaignHandler struct {
+	discountSvc *service.DiscountService
+}
+
+func NewDiscountCampaignHandler(router *gin.RouterGroup, discountSvc *service.DiscountService) {
+	h := discountCampaignHandler{discountSvc: discountSvc}
+
+	path := "/discount-campaigns"
+	router.POST(path, h.Store)
+	router.GET(path, h.Find)
+}
+
+func (h *discountCampaignHandler) Find(c *gin.Context) {
+	req := new(request.DiscountCampaignCriteria)
+	req.Search = c.Query("search")
+	req.Pagination = util.GeneratePaginationFromRequest(c)
+
+	res, err := h.discountSvc.Find(c, req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, res)
+	return
+}
+
+func (h *discountCampaignHandler) Store(c *gin.Context) {
+	req := new(request.DiscountCampaignAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	err := h.discountSvc.Store(c, req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: nil})
+	return
+}
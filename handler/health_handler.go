@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	healthSvc *service.HealthService
+}
+
+// NewHealthHandler registers the probes at the root of router, outside of /api.
+func NewHealthHandler(router gin.IRouter, healthSvc *service.HealthService) {
+	h := healthHandler{healthSvc: healthSvc}
+
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+}
+
+// Live only tells the process is serving, it doesn't depend on the database so that an outage doesn't get
+// every instance restarted.
+func (h *healthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, response.HealthResponse{Status: "ok"})
+}
+
+func (h *healthHandler) Ready(c *gin.Context) {
+	res, ok := h.healthSvc.Ready(c)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"interview-telkom-6/logger"
+	"interview-telkom-6/metrics"
+	"interview-telkom-6/service"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestLogger gives every request an id, taken from the X-Request-ID header or generated, and stores it in
+// the request context so that every record logged while serving it carries the id. Once served, the request
+// is logged with its status, latency and the errors recorded through util.BuildErrorAPI, as an error for 5xx
+// responses and a warning for 4xx ones. The engine needs ContextWithFallback for services to see the id.
+func RequestLogger(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = logger.NewRequestID()
+		}
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Header(requestIDHeader, id)
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(c.Errors.Errors(), "; ")))
+		}
+		log.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Tracing starts the server span of every request, continuing the trace of the caller when it sends a W3C
+// traceparent header, and stores it in the request context so that the spans of services and repositories
+// are its children. 5xx responses mark the span as failed.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Start(
+			ctx, c.Request.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method), semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Metrics counts and times the requests by method, route and status. Requests matching no route are grouped
+// under "unmatched" so that scanners can't blow up the number of series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := []string{c.Request.Method, route, strconv.Itoa(c.Writer.Status())}
+		metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+		metrics.HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ConditionalGET tags the 200 responses of a route with an ETag, the hash of their body, so that a client
+// sending it back in If-None-Match gets a 304 without body as long as the result is the same. The body is
+// still computed, only the download is saved. cacheControl, when set, is sent along to let clients and CDNs
+// keep the response for a while before revalidating it.
+func ConditionalGET(cacheControl string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if c.Writer.Status() != http.StatusOK {
+			_, _ = c.Writer.Write(w.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(w.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		c.Header("ETag", etag)
+		if cacheControl != "" {
+			c.Header("Cache-Control", cacheControl)
+		}
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			c.Writer.Header().Del("Content-Type")
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		_, _ = c.Writer.Write(w.body.Bytes())
+	}
+}
+
+// etagMatches tells whether etag is in the If-None-Match header value, which compares tags weakly.
+func etagMatches(header string, etag string) bool {
+	for _, val := range strings.Split(header, ",") {
+		val = strings.TrimPrefix(strings.TrimSpace(val), "W/")
+		if val == "*" || val == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedWriter holds the body back until ConditionalGET knows whether to send it.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests sent with an Idempotency-Key header safe to retry.
+// The first request with a key is served and its response recorded, a retry of the same request, with the
+// same method, URL and body, gets that response back with an Idempotent-Replayed header instead of being
+// served again. Reusing a key for another request gets a 422 and a retry while the first request is still
+// being served a 409, until the claim timeout passes and the retry is served. 408, 409, 425, 429 and 5xx
+// responses aren't recorded, their retry is served again.
+func Idempotency(idempotencySvc *service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			key = ""
+		}
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		// no route accepts more than an import
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+		if err != nil {
+			util.BuildErrorAPI(c, &util.BadRequestError{Message: err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		replay, err := idempotencySvc.Begin(c, key, hex.EncodeToString(hash.Sum(nil)))
+		if err != nil {
+			util.BuildErrorAPI(c, err)
+			return
+		}
+		if replay != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(int(replay.Status.Int64), replay.ContentType.String, replay.Body)
+			c.Abort()
+			return
+		}
+
+		// the response is recorded even when the client is gone, its retry is what the key is for
+		ctx := context.WithoutCancel(c.Request.Context())
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		served := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			status := c.Writer.Status()
+			if !served {
+				// the handler panicked, the recovery middleware answers with a 500
+				status = http.StatusInternalServerError
+			}
+			err := idempotencySvc.Finish(ctx, key, status, c.Writer.Header().Get("Content-Type"), w.body.Bytes())
+			if err != nil {
+				_ = c.Error(err)
+			}
+		}()
+
+		c.Next()
+		served = true
+	}
+}
+
+// recordingWriter keeps a copy of the body it writes.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type paymentHandler struct {
+	paymentSvc *service.PaymentService
+}
+
+func NewPaymentHandler(router *gin.RouterGroup, paymentSvc *service.PaymentService) {
+	h := paymentHandler{paymentSvc: paymentSvc}
+
+	path := "/payments"
+	router.POST(path, h.Store)
+	router.POST(fmt.Sprintf("%s/webhook", path), h.Webhook)
+	router.POST(fmt.Sprintf("%s/:id/refunds", path), h.Refund)
+	// orders are the payments of carts for now, an order shares the id of its payment
+	router.GET("/orders/:id/invoice", h.Invoice)
+}
+
+func (h *paymentHandler) Store(c *gin.Context) {
+	req := new(request.PaymentAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	res, err := h.paymentSvc.Store(c, req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *paymentHandler) Refund(c *gin.Context) {
+	req := new(request.RefundAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	res, err := h.paymentSvc.Refund(c, c.Param("id"), req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *paymentHandler) Invoice(c *gin.Context) {
+	name, data, err := h.paymentSvc.Invoice(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	c.Data(http.StatusOK, "application/pdf", data)
+	return
+}
+
+// Webhook receives status changes from the payment provider, the raw body is needed to check the signature.
+func (h *paymentHandler) Webhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		util.BuildErrorAPI(c, &util.BadRequestError{Message: "invalid webhook payload"})
+		return
+	}
+
+	err = h.paymentSvc.HandleWebhook(c, payload, c.GetHeader("X-Signature"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success handled webhook"})
+	return
+}
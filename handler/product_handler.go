@@ -1,34 +1,46 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"interview-telkom-6/request"
 	"interview-telkom-6/response"
 	"interview-telkom-6/service"
 	"interview-telkom-6/util"
-	"log"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type productHandler struct {
 	productSvc *service.ProductService
 }
 
-func NewProductHandler(router *gin.RouterGroup, productSvc *service.ProductService) {
+// NewProductHandler registers the product routes. The listing answers conditional GETs and is sent with
+// cacheControl, if any.
+func NewProductHandler(router *gin.RouterGroup, productSvc *service.ProductService, cacheControl string) {
 	h := productHandler{productSvc: productSvc}
 
 	path := "/products"
 	router.POST(path, h.Store)
-	router.GET(path, h.Find)
+	router.GET(path, ConditionalGET(cacheControl), h.Find)
+	router.PUT(path+"/:id", h.Update)
+	router.POST(path+"/import", h.Import)
+	router.GET(path+"/export", h.Export)
 }
 
+// maxImportSize bounds the size of an uploaded catalogue.
+const maxImportSize = 10 << 20
+
 func (h *productHandler) Find(c *gin.Context) {
 	req := new(request.ProductCriteria)
 
 	pagination := util.GeneratePaginationFromRequest(c)
 	req.Search = c.Query("search")
 	req.Popular = c.Query("popular")
+	req.Currency = c.Query("currency")
 	req.Pagination = pagination
 
 	res, err := h.productSvc.Find(c, req)
@@ -44,8 +56,7 @@ func (h *productHandler) Find(c *gin.Context) {
 func (h *productHandler) Store(c *gin.Context) {
 	req := new(request.ProductAddRequest)
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Println(err)
-
+		_ = c.Error(err)
 		c.AbortWithStatusJSON(
 			http.StatusBadRequest, response.ErrorResponse{
 				Message: "StatusBadRequest",
@@ -70,7 +81,6 @@ func (h *productHandler) Store(c *gin.Context) {
 
 	err := h.productSvc.Store(c, req)
 	if err != nil {
-		log.Println(err)
 		util.BuildErrorAPI(c, err)
 		return
 	}
@@ -78,3 +88,125 @@ func (h *productHandler) Store(c *gin.Context) {
 	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: nil})
 	return
 }
+
+// Update replaces a product. The request may carry the version of the product it was based on in an
+// If-Match header, the ETag of the response being the new version, in which case it fails with 412 when
+// another request changed the product in between.
+func (h *productHandler) Update(c *gin.Context) {
+	req := new(request.ProductAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	ifMatch, err := parseVersionETag(c.GetHeader("If-Match"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	res, err := h.productSvc.Update(c, c.Param("id"), req, ifMatch)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(res.Version, 10)))
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success updated data", Data: res})
+}
+
+// parseVersionETag returns the version of an If-Match header, nil when it is empty or "*" as the product has
+// to exist anyway. Weak tags, lists and other values can't match a version.
+func parseVersionETag(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || header != strconv.Quote(strconv.FormatInt(version, 10)) {
+		return nil, &util.PreconditionFailedError{Message: "If-Match must be the ETag of the product"}
+	}
+
+	return &version, nil
+}
+
+// Import reads the catalogue from the request body, in the format given by the format query parameter or
+// else by the Content-Type. The report is sent with 400 when any row was rejected, and with 409 when a row
+// clashed with a concurrent write, the rows before it being written.
+func (h *productHandler) Import(c *gin.Context) {
+	req := new(request.ProductImportRequest)
+	req.Format = strings.ToLower(c.Query("format"))
+	if req.Format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			req.Format = request.ImportFormatCSV
+		case "application/x-ndjson", "application/jsonl":
+			req.Format = request.ImportFormatJSONL
+		}
+	}
+	req.DryRun, _ = strconv.ParseBool(c.Query("dry_run"))
+	req.Upsert, _ = strconv.ParseBool(c.Query("upsert"))
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	res, err := h.productSvc.Import(c, req, body)
+	var conflict *util.ConflictError
+	if errors.As(err, &conflict) && res.Failed > 0 {
+		c.JSONP(http.StatusConflict, response.SuccessResponse{Status: "failed", Message: "StatusConflict", Data: res})
+		return
+	}
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	if res.Failed > 0 {
+		c.JSONP(http.StatusBadRequest, response.SuccessResponse{Status: "failed", Message: "StatusBadRequest", Data: res})
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success import data", Data: res})
+	return
+}
+
+// Export streams the products matching the same filters as Find, the format query parameter picks csv,
+// jsonl or xlsx. Once streaming started an error can only cut the download short.
+func (h *productHandler) Export(c *gin.Context) {
+	req := new(request.ProductExportRequest)
+	req.Search = c.Query("search")
+	req.Currency = c.Query("currency")
+	req.Format = strings.ToLower(c.DefaultQuery("format", request.ImportFormatCSV))
+
+	contentType, ok := service.ExportContentType(req.Format)
+	if !ok {
+		util.BuildErrorAPI(
+			c, util.NewValidationError([]util.FieldError{{Field: "format", Message: "must be csv, jsonl or xlsx"}}),
+		)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "products."+req.Format))
+	c.Status(http.StatusOK)
+
+	if err := h.productSvc.Export(c, req, c.Writer); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		util.BuildErrorAPI(c, err)
+		return
+	}
+}
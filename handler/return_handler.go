@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type returnHandler struct {
+	returnSvc *service.ReturnService
+}
+
+func NewReturnHandler(router *gin.RouterGroup, returnSvc *service.ReturnService) {
+	h := returnHandler{returnSvc: returnSvc}
+
+	path := "/returns"
+	router.POST("/payments/:id/returns", h.Store)
+	router.POST(fmt.Sprintf("%s/:id/approve", path), h.Approve)
+	router.POST(fmt.Sprintf("%s/:id/reject", path), h.Reject)
+	router.POST(fmt.Sprintf("%s/:id/receive", path), h.Receive)
+	router.POST(fmt.Sprintf("%s/:id/refund", path), h.Refund)
+}
+
+func (h *returnHandler) Store(c *gin.Context) {
+	req := new(request.ReturnAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	res, err := h.returnSvc.Store(c, c.Param("id"), req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *returnHandler) Approve(c *gin.Context) {
+	res, err := h.returnSvc.Approve(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *returnHandler) Reject(c *gin.Context) {
+	res, err := h.returnSvc.Reject(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *returnHandler) Receive(c *gin.Context) {
+	res, err := h.returnSvc.Receive(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
+
+func (h *returnHandler) Refund(c *gin.Context) {
+	res, err := h.returnSvc.Refund(c, c.Param("id"))
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: res})
+	return
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type taxRuleHandler struct {
+	taxSvc *service.TaxService
+}
+
+func NewTaxRuleHandler(router *gin.RouterGroup, taxSvc *service.TaxService) {
+	h := taxRuleHandler{taxSvc: taxSvc}
+
+	path := "/admin/tax-rules"
+	router.POST(path, h.Store)
+	router.GET(path, h.Find)
+}
+
+func (h *taxRuleHandler) Find(c *gin.Context) {
+	res, err := h.taxSvc.Find(c)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success get data", Data: res})
+	return
+}
+
+func (h *taxRuleHandler) Store(c *gin.Context) {
+	req := new(request.TaxRuleAddRequest)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.JSON(
+			http.StatusBadRequest, response.ErrorResponse{
+				Message: "StatusBadRequest",
+				Error:   err.Error(),
+				Status:  "failed",
+			},
+		)
+		return
+	}
+
+	err := h.taxSvc.Store(c, req)
+	if err != nil {
+		util.BuildErrorAPI(c, err)
+		return
+	}
+
+	c.JSONP(http.StatusOK, response.SuccessResponse{Status: "success", Message: "success saved data", Data: nil})
+	return
+}
@@ -6,16 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"interview-telkom-6/handler"
+	"interview-telkom-6/repository/migration"
 	"interview-telkom-6/repository/persistence"
 	"interview-telkom-6/request"
 	"interview-telkom-6/response"
 	"interview-telkom-6/service"
-	"io/ioutil"
+	"interview-telkom-6/util"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -48,6 +51,12 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
 
+	// skip the whole suite when there is no docker daemon to talk to
+	if err := pool.Client.Ping(); err != nil {
+		log.Printf("docker not available, skipping integration test: %s", err)
+		os.Exit(0)
+	}
+
 	// pulls an image, creates a container based on it and runs it
 	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
 		Name:       containerNamePostgres,
@@ -96,29 +105,55 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to database: %s", err)
 	}
 
-	file, err := ioutil.ReadFile("../order-on-table.sql")
+	ctx := context.Background()
+	migrator, err := migration.NewMigrator(db)
 	if err != nil {
-		log.Fatalf("error opening file sql: %v", err)
+		log.Fatalf("error load migrations: %v", err)
 	}
-
-	_, err = db.Exec(string(file))
-	if err != nil {
+	if _, err := migrator.Up(ctx); err != nil {
 		log.Fatalf("error create table: %v", err)
 	}
 
-	ctx := context.Background()
 	ctx = context.WithValue(ctx, "db", db)
 	r = gin.New()
 	rGroup := r.Group("/api")
 
-	productRepo := persistence.NewProductRepository(db)
-	cartRepo := persistence.NewCartRepository(db)
-	cartProductRepo := persistence.NewCartProductRepository(db)
-	productSvc := service.NewProductService(productRepo)
-	cartSvc := service.NewCartService(ctx, cartRepo, cartProductRepo, productRepo)
-
-	handler.NewProductHandler(rGroup, productSvc)
+	productRepo := persistence.NewProductRepository(db, slog.Default(), 5*time.Second)
+	cartRepo := persistence.NewCartRepository(db, slog.Default(), 5*time.Second)
+	cartProductRepo := persistence.NewCartProductRepository(db, slog.Default(), 5*time.Second)
+	campaignRepo := persistence.NewDiscountCampaignRepository(db, slog.Default(), 5*time.Second)
+	campaignTargetRepo := persistence.NewDiscountCampaignTargetRepository(db, slog.Default(), 5*time.Second)
+	exchangeRateRepo := persistence.NewExchangeRateRepository(db, slog.Default(), 5*time.Second)
+	taxRuleRepo := persistence.NewTaxRuleRepository(db, slog.Default(), 5*time.Second)
+	addressRepo := persistence.NewAddressRepository(db, slog.Default(), 5*time.Second)
+	paymentRepo := persistence.NewPaymentRepository(db, slog.Default(), 5*time.Second)
+	refundRepo := persistence.NewRefundRepository(db, slog.Default(), 5*time.Second)
+	returnRepo := persistence.NewReturnRepository(db, slog.Default(), 5*time.Second)
+	fileRepo := persistence.NewFileRepository(db, slog.Default(), 5*time.Second)
+	idempotencyKeyRepo := persistence.NewIdempotencyKeyRepository(db, slog.Default(), 5*time.Second)
+	currencySvc := service.NewCurrencyService(exchangeRateRepo)
+	discountSvc := service.NewDiscountService(ctx, campaignRepo, campaignTargetRepo, currencySvc)
+	taxSvc := service.NewTaxService(taxRuleRepo)
+	addressSvc := service.NewAddressService(ctx, addressRepo)
+	invoiceSvc := service.NewInvoiceService(fileRepo, service.NewLocalFileStorage(os.TempDir()))
+	productSvc := service.NewProductService(ctx, productRepo, discountSvc, currencySvc)
+	cartSvc := service.NewCartService(
+		ctx, cartRepo, cartProductRepo, productRepo, discountSvc, currencySvc, taxSvc, addressSvc,
+		service.NewDefaultShippingCalculator(),
+	)
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentRepo, refundRepo, cartSvc, invoiceSvc, service.NewFakePaymentProvider("secret"),
+	)
+
+	rGroup.Use(handler.Idempotency(service.NewIdempotencyService(idempotencyKeyRepo, time.Minute)))
+	handler.NewProductHandler(rGroup, productSvc, "public, max-age=60")
 	handler.NewCartHandler(rGroup, cartSvc)
+	handler.NewDiscountCampaignHandler(rGroup, discountSvc)
+	handler.NewExchangeRateHandler(rGroup, currencySvc)
+	handler.NewTaxRuleHandler(rGroup, taxSvc)
+	handler.NewAddressHandler(rGroup, addressSvc)
+	handler.NewPaymentHandler(rGroup, paymentSvc)
+	handler.NewReturnHandler(rGroup, service.NewReturnService(ctx, paymentRepo, returnRepo, productRepo, paymentSvc))
 
 	code := m.Run()
 
@@ -133,7 +168,7 @@ func TestMain(m *testing.M) {
 func TestInsertProduct(t *testing.T) {
 	requestData := request.ProductAddRequest{
 		Name:        "Mie Goreng",
-		Price:       20000,
+		Price:       util.MoneyFromInt(20000),
 		Description: "Mie Goreng enak",
 		IsDiscount:  false,
 	}
@@ -167,6 +202,20 @@ func TestFindProduct(t *testing.T) {
 	assert.NoError(t, err)
 
 	productID = data.Data[0].ID
+
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, "/api/products", nil)
+	assert.NoError(t, err)
+	req.Header.Set("If-None-Match", etag)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
 }
 
 func TestAddProductToCart(t *testing.T) {
@@ -221,6 +270,44 @@ func TestAddProductToCartMultipleQuantity(t *testing.T) {
 	assert.Equal(t, qty+qtyAdd, data.Data.Products[0].Quantity)
 }
 
+func TestAddProductToCartIdempotent(t *testing.T) {
+	post := func(quantity int) *httptest.ResponseRecorder {
+		requestData := request.CartAddRequest{
+			FullName: "Retrying Buyer",
+			Product:  request.CartAddProductRequest{ProductID: productID, Quantity: quantity},
+		}
+		b, err := json.Marshal(requestData)
+		assert.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/api/carts", bytes.NewReader(b))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "add-to-cart-1")
+
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post(qty)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	// the retry gets the first response back instead of adding the product again
+	retry := post(qty)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+
+	type resStruct struct {
+		Data response.CartResponse `json:"data"`
+	}
+	data := resStruct{}
+	assert.NoError(t, json.Unmarshal(retry.Body.Bytes(), &data))
+	assert.Equal(t, qty, data.Data.Products[0].Quantity)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, post(qty+1).Code)
+}
+
 func TestFindCart(t *testing.T) {
 	w := httptest.NewRecorder()
 	req, err := http.NewRequest(http.MethodGet, "/api/carts?full_name=Rehan Dwi", nil)
@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// New returns a logger writing records of level and above to w, formatted as json or text. Every record
+// logged with a context carrying a request id gets a request_id attribute, and trace_id and span_id ones when
+// the context carries a span.
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("log level %q must be debug, info, warn or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format %q must be json or text", format)
+	}
+
+	return slog.New(contextHandler{h}), nil
+}
+
+// WithRequestID returns a copy of ctx carrying id, logged with every record of that context.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request id of ctx, empty outside of a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Redact returns SQL arguments safe to log: strings and bytes, which may be customer data such as names
+// or addresses, are replaced by their length while ids, numbers, booleans and times are kept.
+func Redact(args []interface{}) []interface{} {
+	res := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case string:
+			res = append(res, fmt.Sprintf("[redacted %d chars]", len(val)))
+		case []byte:
+			res = append(res, fmt.Sprintf("[redacted %d bytes]", len(val)))
+		case uuid.UUID, []uuid.UUID, bool, int, int64, float64, time.Time, nil:
+			res = append(res, val)
+		case fmt.Stringer:
+			res = append(res, val.String())
+		default:
+			res = append(res, fmt.Sprintf("[redacted %T]", val))
+		}
+	}
+
+	return res
+}
+
+// contextHandler adds the request id and span of the record context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// NewRequestID returns a random request id, for requests arriving without one.
+func NewRequestID() string {
+	return uuid.NewString()
+}
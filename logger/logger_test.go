@@ -0,0 +1,60 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"interview-telkom-6/logger"
+	"testing"
+)
+
+func TestNewAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, "info", "json")
+	assert.NoError(t, err)
+
+	ctx := logger.WithRequestID(context.TODO(), "req-1")
+	log.InfoContext(ctx, "hello", "count", 2)
+	log.DebugContext(ctx, "hidden")
+
+	record := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "hello", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, float64(2), record["count"])
+	assert.NotContains(t, buf.String(), "hidden")
+}
+
+func TestNewAddsSpan(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, "info", "json")
+	assert.NoError(t, err)
+
+	span := trace.NewSpanContext(
+		trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceFlags: trace.FlagsSampled},
+	)
+	log.InfoContext(trace.ContextWithSpanContext(context.TODO(), span), "hello")
+
+	record := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "01000000000000000000000000000000", record["trace_id"])
+	assert.Equal(t, "0200000000000000", record["span_id"])
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := logger.New(&bytes.Buffer{}, "verbose", "json")
+	assert.Error(t, err)
+
+	_, err = logger.New(&bytes.Buffer{}, "info", "xml")
+	assert.Error(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	id := uuid.New()
+	args := logger.Redact([]interface{}{"Rehan", id, 3, true, []byte("secret")})
+
+	assert.Equal(t, []interface{}{"[redacted 5 chars]", id, 3, true, "[redacted 6 bytes]"}, args)
+}
@@ -3,66 +3,147 @@ package main
 import (
 	"context"
 	"fmt"
-	"interview-telkom-6/handler"
-	"interview-telkom-6/repository/persistence"
-	"interview-telkom-6/service"
-	"log"
+	"interview-telkom-6/config"
+	"interview-telkom-6/logger"
+	"interview-telkom-6/repository/migration"
+	"interview-telkom-6/tracing"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
-func init() {
-	os.Setenv("TZ", "Asia/Jakarta")
-}
+const usage = `usage: main [command]
+
+commands:
+  serve                                   run the HTTP API, the default
+  migrate up|down|status                  apply, revert the last or list migrations
+  seed [-file products.jsonl]             import fixture products, updating the ones already there
+  purge-carts -older-than 720h            delete the carts without payment created before
+  purge-idempotency-keys -older-than 24h  delete the idempotency keys recorded before
+  export products [-format csv] [-o file] export the catalogue, to stdout by default`
 
 func main() {
-	log.SetFlags(log.Lshortfile | log.Lmicroseconds)
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Println(usage)
+		return
+	}
+
+	if err := run(command, args); err != nil {
+		slog.Error("exiting", "command", command, "error", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the configuration, connects to the database and runs command, returning once it is done.
+func run(command string, args []string) error {
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile == "" {
+		configFile = "config.yaml"
+	}
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	time.Local = cfg.Location()
 
-	r := gin.New()
-	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
-	rGroup := r.Group("/api")
+	log, err := logger.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return err
+	}
+	// services and the standard log package log through the default logger
+	slog.SetDefault(log)
 
-	gin.SetMode(gin.ReleaseMode)
+	// stopping cancels ctx, which interrupts the startup and shuts the server down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err := godotenv.Load(); err != nil {
-		log.Printf(".env not found")
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		// ctx may be cancelled by now, the pending spans get their own deadline to be exported
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Warn("flush traces", "error", err)
+		}
+	}()
 
-	dbUser := os.Getenv("DATABASE_USER")
-	dbPass := os.Getenv("DATABASE_PASSWORD")
-	dbPort := os.Getenv("DATABASE_PORT")
-	dbHost := os.Getenv("DATABASE_HOST")
-	dbName := os.Getenv("DATABASE_NAME")
-	ctx := context.Background()
-
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta", dbHost, dbUser,
-		dbPass,
-		dbName, dbPort,
-	)
-	db, err := sqlx.Open("postgres", dsn)
+	db, err := sqlx.Open("postgres", cfg.Database.DSN(cfg.App.Timezone))
 	if err != nil {
-		log.Fatalf("error connect to db: %v", err)
+		return fmt.Errorf("connect to db: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
 
 	defer db.Close()
 
+	if err := pingDB(ctx, db, cfg.Database); err != nil {
+		return fmt.Errorf("connect to db: %w", err)
+	}
+
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
 	ctx = context.WithValue(ctx, "db", db)
+	a := newApp(ctx, db, cfg, log)
 
-	productRepo := persistence.NewProductRepository(db)
-	cartRepo := persistence.NewCartRepository(db)
-	cartProductRepo := persistence.NewCartProductRepository(db)
-	productSvc := service.NewProductService(productRepo)
-	cartSvc := service.NewCartService(ctx, cartRepo, cartProductRepo, productRepo)
+	switch command {
+	case "serve":
+		if cfg.Features.AutoMigrate {
+			err = runMigrate(ctx, migrator, nil)
+		}
+		if err == nil {
+			err = a.serve(ctx, migrator)
+		}
+	case "migrate":
+		err = runMigrate(ctx, migrator, args)
+	case "seed":
+		err = a.seed(ctx, args)
+	case "purge-carts":
+		err = a.purgeCarts(ctx, args)
+	case "purge-idempotency-keys":
+		err = a.purgeIdempotencyKeys(ctx, args)
+	case "export":
+		err = a.export(ctx, args)
+	default:
+		err = fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
 
-	handler.NewProductHandler(rGroup, productSvc)
-	handler.NewCartHandler(rGroup, cartSvc)
+	return err
+}
 
-	log.Fatal(r.Run(":" + os.Getenv("APP_PORT")))
+// pingDB checks the database is reachable, sql.Open only validating the DSN. Failed pings are retried
+// cfg.ConnectRetries times, waiting cfg.ConnectBackoff then twice as long after each attempt.
+func pingDB(ctx context.Context, db *sqlx.DB, cfg config.DatabaseConfig) (err error) {
+	backoff := cfg.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt >= cfg.ConnectRetries {
+			return err
+		}
 
+		slog.WarnContext(ctx, "database not reachable, retrying", "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v: %w", err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
 }
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shop"
+
+// Registry holds every collector of the application, along with the Go runtime and process ones. It is
+// used instead of the default registry so that libraries can't add their own metrics behind our back.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status.",
+		}, []string{"method", "route", "status"},
+	)
+	HTTPDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"},
+	)
+	DBQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by repository methods to query the database, by table and method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"table", "method"},
+	)
+	CartsCreated = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "carts_created_total",
+			Help:      "Carts created by adding a first product.",
+		},
+	)
+	CartItemsAdded = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cart_items_added_total",
+			Help:      "Product units added to carts.",
+		},
+	)
+	Checkouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "checkouts_total",
+			Help:      "Payments created for a cart.",
+		},
+	)
+	CacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_hits_total",
+			Help:      "Reads served from cache, by cache.",
+		}, []string{"cache"},
+	)
+	CacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_misses_total",
+			Help:      "Reads that went to the database as the cache didn't have them, by cache.",
+		}, []string{"cache"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, DBQueryDuration, CartsCreated, CartItemsAdded, Checkouts, CacheHits, CacheMisses,
+	)
+}
+
+// RegisterDB exposes the connection pool statistics of db, such as open, in use and idle connections and the
+// time spent waiting for one.
+func RegisterDB(db *sqlx.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db.DB, name))
+}
+
+// ObserveQuery records the time a repository method took since start.
+func ObserveQuery(table string, method string, start time.Time) {
+	DBQueryDuration.WithLabelValues(table, method).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the metrics of Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,41 @@
+package metrics_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestObserveQuery(t *testing.T) {
+	metrics.ObserveQuery("products", "Find", time.Now().Add(-30*time.Millisecond))
+
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.DBQueryDuration))
+
+	res := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `shop_db_query_duration_seconds_count{method="Find",table="products"} 1`)
+	assert.Contains(t, res.Body.String(), `shop_db_query_duration_seconds_bucket{method="Find",table="products",le="0.025"} 0`)
+	assert.Contains(t, res.Body.String(), `shop_db_query_duration_seconds_bucket{method="Find",table="products",le="0.05"} 1`)
+}
+
+func TestRegisterDB(t *testing.T) {
+	// opening doesn't connect, the pool statistics are there all the same
+	db, err := sqlx.Open("postgres", "host=localhost dbname=shop")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, metrics.RegisterDB(db, "shop"))
+	assert.Error(t, metrics.RegisterDB(db, "shop"))
+
+	res := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, res.Body.String(), `go_sql_open_connections{db_name="shop"} 0`)
+	assert.Contains(t, res.Body.String(), `go_sql_max_open_connections{db_name="shop"} 0`)
+}
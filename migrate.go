@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/repository/migration"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate runs `migrate up`, `migrate down` (reverting the last migration) or `migrate status`.
+func runMigrate(ctx context.Context, migrator *migration.Migrator, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, val := range applied {
+			slog.InfoContext(ctx, "applied migration", "version", val.Version, "name", val.Name)
+		}
+		if len(applied) == 0 {
+			slog.InfoContext(ctx, "no pending migration")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			slog.InfoContext(ctx, "no applied migration")
+			return nil
+		}
+		slog.InfoContext(ctx, "reverted migration", "version", reverted.Version, "name", reverted.Name)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, val := range status {
+			appliedAt := "pending"
+			if val.AppliedAt != nil {
+				appliedAt = val.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", val.Version, val.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey is the advisory lock held while a migration runs, so that replicas starting together don't apply
+// the same migration twice.
+const lockKey = 7205861
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a numbered schema change with the SQL applying and reverting it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and the time it was applied at, nil when it is pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator returns a migrator running the migrations embedded in the binary.
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load reads the migrations of the sql directory of fsys, named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, sorted by version. Every version must have both files.
+func Load(fsys fs.FS) (res []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: named both %s and %s", version, m.Name, match[2])
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s: both up and down files are required", m.Version, m.Name)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+
+	return res, nil
+}
+
+// Up applies every pending migration in version order, each one in its own transaction, and returns the
+// applied ones.
+func (m *Migrator) Up(ctx context.Context) (res []Migration, err error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		applied, err := m.run(ctx, migration, true)
+		if err != nil {
+			return res, err
+		}
+		if applied {
+			res = append(res, migration)
+		}
+	}
+
+	return res, nil
+}
+
+// Down reverts the last applied migration and returns it, nil when none is applied.
+func (m *Migrator) Down(ctx context.Context) (res *Migration, err error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if _, err := m.run(ctx, migration, false); err != nil {
+			return nil, err
+		}
+		return &migration, nil
+	}
+
+	return nil, nil
+}
+
+// Status lists every migration with the time it was applied at.
+func (m *Migrator) Status(ctx context.Context) (res []Status, err error) {
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		res = append(res, status)
+	}
+
+	return res, nil
+}
+
+// Pending returns the migrations not applied yet.
+func (m *Migrator) Pending(ctx context.Context) (res []Migration, err error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, val := range status {
+		if val.AppliedAt == nil {
+			res = append(res, val.Migration)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(
+		ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version integer PRIMARY KEY,
+    name character varying(100) NOT NULL,
+    applied_at timestamp without time zone DEFAULT now() NOT NULL
+)`,
+	)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (res map[int]time.Time, err error) {
+	rows := []struct {
+		Version   int       `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}{}
+	if err := m.db.SelectContext(ctx, &rows, "SELECT version, applied_at FROM schema_migrations"); err != nil {
+		return nil, err
+	}
+
+	res = make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		res[row.Version] = row.AppliedAt
+	}
+
+	return res, nil
+}
+
+// run applies or reverts migration unless another process already did, in which case it returns false.
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) (ok bool, err error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+		return false, err
+	}
+
+	var count int
+	err = tx.GetContext(ctx, &count, "SELECT count(*) FROM schema_migrations WHERE version = $1", migration.Version)
+	if err != nil {
+		return false, err
+	}
+	if (count == 1) == up {
+		return false, nil
+	}
+
+	script := migration.Up
+	record := "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)"
+	if !up {
+		script = migration.Down
+		record = "DELETE FROM schema_migrations WHERE version = $1"
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return false, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	args := []interface{}{migration.Version}
+	if up {
+		args = append(args, migration.Name)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
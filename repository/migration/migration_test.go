@@ -0,0 +1,50 @@
+package migration_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/repository/migration"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := migration.Load(os.DirFS("."))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, val := range migrations {
+		assert.Equal(t, i+1, val.Version)
+		assert.NotEmpty(t, val.Up)
+		assert.NotEmpty(t, val.Down)
+	}
+}
+
+func TestLoadSorted(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+		"sql/0002_second.down.sql": {Data: []byte("SELECT -2")},
+		"sql/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+		"sql/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+	}
+
+	migrations, err := migration.Load(fsys)
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, "first", migrations[0].Name)
+	assert.Equal(t, "SELECT -2", migrations[1].Down)
+}
+
+func TestLoadMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{"sql/0001_first.up.sql": {Data: []byte("SELECT 1")}}
+
+	_, err := migration.Load(fsys)
+	assert.Error(t, err)
+}
+
+func TestLoadInvalidName(t *testing.T) {
+	fsys := fstest.MapFS{"sql/first.sql": {Data: []byte("SELECT 1")}}
+
+	_, err := migration.Load(fsys)
+	assert.Error(t, err)
+}
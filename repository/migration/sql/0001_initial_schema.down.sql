@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS public.refunds;
+DROP TABLE IF EXISTS public.payments;
+DROP TABLE IF EXISTS public.addresses;
+DROP TABLE IF EXISTS public.tax_rules;
+DROP TABLE IF EXISTS public.exchange_rates;
+DROP TABLE IF EXISTS public.discount_campaign_targets;
+DROP TABLE IF EXISTS public.discount_campaigns;
+DROP TABLE IF EXISTS public.cart_products;
+DROP TABLE IF EXISTS public.carts;
+DROP TABLE IF EXISTS public.products;
+DROP TABLE IF EXISTS public.files;
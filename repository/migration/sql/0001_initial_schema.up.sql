@@ -0,0 +1,274 @@
+-- Creates the schema, or adopts one created from table.sql before migrations existed, then adds the columns, keys
+-- and indexes it lacked.
+
+CREATE TABLE IF NOT EXISTS public.cart_products (
+    cart_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    quantity integer NOT NULL
+);
+
+
+--
+-- Name: carts; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.carts (
+    id uuid NOT NULL,
+    full_name character varying(50) NOT NULL,
+    created_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
+
+--
+-- Name: files; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.files (
+    id uuid NOT NULL,
+    name character varying(50) NOT NULL,
+    location text NOT NULL,
+    bucket_name character varying(50) NOT NULL
+);
+
+
+--
+-- Name: products; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.products (
+    id uuid NOT NULL,
+    name character varying(50) NOT NULL,
+    price numeric(21,2) NOT NULL,
+    currency character(3) DEFAULT 'IDR'::bpchar NOT NULL,
+    description text NOT NULL,
+    weight integer DEFAULT 0 NOT NULL,
+    category character varying(50),
+    is_discount boolean NOT NULL,
+    discount_type character varying(20),
+    start_date_discount date,
+    end_date_discount date,
+    discount_value numeric(21,2)
+);
+
+
+--
+-- Name: discount_campaigns; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.discount_campaigns (
+    id uuid NOT NULL,
+    name character varying(50) NOT NULL,
+    discount_type character varying(20) NOT NULL,
+    value numeric(21,2) NOT NULL,
+    priority integer DEFAULT 0 NOT NULL,
+    is_stackable boolean DEFAULT false NOT NULL,
+    start_date date NOT NULL,
+    end_date date NOT NULL
+);
+
+
+--
+-- Name: discount_campaign_targets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.discount_campaign_targets (
+    campaign_id uuid NOT NULL,
+    product_id uuid,
+    category character varying(50)
+);
+
+
+--
+-- Name: exchange_rates; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.exchange_rates (
+    base_currency character(3) NOT NULL,
+    quote_currency character(3) NOT NULL,
+    rate numeric(30,10) NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: tax_rules; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.tax_rules (
+    id uuid NOT NULL,
+    name character varying(50) NOT NULL,
+    category character varying(50),
+    rate numeric(5,2) NOT NULL,
+    is_inclusive boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: addresses; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.addresses (
+    id uuid NOT NULL,
+    full_name character varying(50) NOT NULL,
+    label character varying(50) NOT NULL,
+    recipient character varying(50) NOT NULL,
+    phone character varying(20) NOT NULL,
+    street text NOT NULL,
+    city character varying(50) NOT NULL,
+    province character varying(50) NOT NULL,
+    postal_code character varying(10) NOT NULL,
+    country character(2) NOT NULL,
+    is_default boolean DEFAULT false NOT NULL
+);
+
+
+--
+-- Name: payments; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.payments (
+    id uuid NOT NULL,
+    cart_id uuid NOT NULL,
+    provider character varying(20) NOT NULL,
+    provider_ref character varying(100) NOT NULL,
+    amount numeric(21,2) NOT NULL,
+    currency character(3) NOT NULL,
+    status character varying(20) NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: refunds; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE IF NOT EXISTS public.refunds (
+    id uuid NOT NULL,
+    payment_id uuid NOT NULL,
+    provider_ref character varying(100) NOT NULL,
+    amount numeric(21,2) NOT NULL,
+    reason text NOT NULL,
+    created_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Columns added to the tables of table.sql since, which CREATE TABLE IF NOT EXISTS leaves out of a database
+-- created from it. A discount without type is read as a fixed amount, as it was.
+--
+
+ALTER TABLE public.carts ADD COLUMN IF NOT EXISTS created_at timestamp without time zone DEFAULT now() NOT NULL;
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS currency character(3) DEFAULT 'IDR'::bpchar NOT NULL;
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS weight integer DEFAULT 0 NOT NULL;
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS category character varying(50);
+ALTER TABLE public.products ADD COLUMN IF NOT EXISTS discount_type character varying(20);
+
+
+--
+-- Products sharing a name would break products_name_key. The one with the smallest id is kept, the cart lines
+-- and campaign targets of the others are moved to it before they are deleted.
+--
+
+UPDATE public.cart_products cp SET product_id = k.id
+FROM public.products p
+JOIN (SELECT DISTINCT ON (name) name, id FROM public.products ORDER BY name, id) k ON k.name = p.name
+WHERE cp.product_id = p.id AND p.id <> k.id;
+
+UPDATE public.discount_campaign_targets t SET product_id = k.id
+FROM public.products p
+JOIN (SELECT DISTINCT ON (name) name, id FROM public.products ORDER BY name, id) k ON k.name = p.name
+WHERE t.product_id = p.id AND p.id <> k.id;
+
+DELETE FROM public.products p USING public.products k WHERE k.name = p.name AND k.id < p.id;
+
+
+--
+-- Lines left behind by deleted carts or products would break the foreign keys.
+--
+
+DELETE FROM public.cart_products cp
+WHERE NOT EXISTS (SELECT 1 FROM public.carts c WHERE c.id = cp.cart_id)
+   OR NOT EXISTS (SELECT 1 FROM public.products p WHERE p.id = cp.product_id);
+
+DELETE FROM public.discount_campaign_targets t
+WHERE NOT EXISTS (SELECT 1 FROM public.discount_campaigns c WHERE c.id = t.campaign_id)
+   OR (t.product_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM public.products p WHERE p.id = t.product_id));
+
+
+--
+-- Lines of a cart for the same product, some of them moved above, would break cart_products_pkey: their
+-- quantities are added up on one of them.
+--
+
+UPDATE public.cart_products a
+SET quantity = (
+    SELECT sum(b.quantity) FROM public.cart_products b WHERE b.cart_id = a.cart_id AND b.product_id = a.product_id
+)
+WHERE EXISTS (
+    SELECT 1 FROM public.cart_products b
+    WHERE b.cart_id = a.cart_id AND b.product_id = a.product_id AND b.ctid <> a.ctid
+);
+
+DELETE FROM public.cart_products a USING public.cart_products b
+WHERE b.cart_id = a.cart_id AND b.product_id = a.product_id AND b.ctid < a.ctid;
+
+
+--
+-- Primary keys
+--
+
+ALTER TABLE public.carts ADD CONSTRAINT carts_pkey PRIMARY KEY (id);
+ALTER TABLE public.products ADD CONSTRAINT products_pkey PRIMARY KEY (id);
+ALTER TABLE public.cart_products ADD CONSTRAINT cart_products_pkey PRIMARY KEY (cart_id, product_id);
+ALTER TABLE public.files ADD CONSTRAINT files_pkey PRIMARY KEY (id);
+ALTER TABLE public.discount_campaigns ADD CONSTRAINT discount_campaigns_pkey PRIMARY KEY (id);
+ALTER TABLE public.exchange_rates ADD CONSTRAINT exchange_rates_pkey PRIMARY KEY (base_currency, quote_currency);
+ALTER TABLE public.tax_rules ADD CONSTRAINT tax_rules_pkey PRIMARY KEY (id);
+ALTER TABLE public.addresses ADD CONSTRAINT addresses_pkey PRIMARY KEY (id);
+ALTER TABLE public.payments ADD CONSTRAINT payments_pkey PRIMARY KEY (id);
+ALTER TABLE public.refunds ADD CONSTRAINT refunds_pkey PRIMARY KEY (id);
+
+
+--
+-- Unique constraints
+--
+
+ALTER TABLE public.products ADD CONSTRAINT products_name_key UNIQUE (name);
+
+
+--
+-- Foreign keys
+--
+
+ALTER TABLE public.cart_products
+    ADD CONSTRAINT cart_products_cart_id_fkey FOREIGN KEY (cart_id) REFERENCES public.carts (id) ON DELETE CASCADE;
+ALTER TABLE public.cart_products
+    ADD CONSTRAINT cart_products_product_id_fkey FOREIGN KEY (product_id) REFERENCES public.products (id) ON DELETE CASCADE;
+ALTER TABLE public.discount_campaign_targets
+    ADD CONSTRAINT discount_campaign_targets_campaign_id_fkey FOREIGN KEY (campaign_id)
+        REFERENCES public.discount_campaigns (id) ON DELETE CASCADE;
+ALTER TABLE public.discount_campaign_targets
+    ADD CONSTRAINT discount_campaign_targets_product_id_fkey FOREIGN KEY (product_id)
+        REFERENCES public.products (id) ON DELETE CASCADE;
+ALTER TABLE public.payments
+    ADD CONSTRAINT payments_cart_id_fkey FOREIGN KEY (cart_id) REFERENCES public.carts (id);
+ALTER TABLE public.refunds
+    ADD CONSTRAINT refunds_payment_id_fkey FOREIGN KEY (payment_id) REFERENCES public.payments (id);
+
+
+--
+-- Indexes
+--
+
+CREATE INDEX carts_full_name_idx ON public.carts (full_name);
+CREATE INDEX carts_created_at_idx ON public.carts (created_at);
+CREATE INDEX cart_products_product_id_idx ON public.cart_products (product_id);
+CREATE INDEX products_category_idx ON public.products (category);
+CREATE INDEX discount_campaign_targets_campaign_id_idx ON public.discount_campaign_targets (campaign_id);
+CREATE INDEX discount_campaign_targets_product_id_idx ON public.discount_campaign_targets (product_id);
+CREATE INDEX discount_campaign_targets_category_idx ON public.discount_campaign_targets (category);
+CREATE INDEX addresses_full_name_idx ON public.addresses (full_name);
+CREATE INDEX payments_cart_id_idx ON public.payments (cart_id);
+CREATE INDEX payments_provider_ref_idx ON public.payments (provider, provider_ref);
+CREATE INDEX refunds_payment_id_idx ON public.refunds (payment_id);
@@ -0,0 +1,15 @@
+-- Records the requests sent with an Idempotency-Key header, so that retries get the first response back.
+-- status is null while the first request is being served.
+
+CREATE TABLE public.idempotency_keys (
+    key character varying(255) NOT NULL,
+    request_hash character(64) NOT NULL,
+    status integer,
+    content_type character varying(100),
+    body bytea,
+    created_at timestamp without time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE public.idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key);
+
+CREATE INDEX idempotency_keys_created_at_idx ON public.idempotency_keys (created_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS public.carts_full_name_key;
+CREATE INDEX IF NOT EXISTS carts_full_name_idx ON public.carts (full_name);
+
+ALTER TABLE public.products DROP COLUMN IF EXISTS version;
+ALTER TABLE public.cart_products DROP COLUMN IF EXISTS version;
+ALTER TABLE public.carts DROP COLUMN IF EXISTS version;
@@ -0,0 +1,36 @@
+-- Adds the version that conditional updates compare and bump, so that concurrent writes can't overwrite each
+-- other unnoticed, and makes a customer's cart unique so that two requests can't both create it.
+
+ALTER TABLE public.carts ADD COLUMN version integer DEFAULT 1 NOT NULL;
+ALTER TABLE public.cart_products ADD COLUMN version integer DEFAULT 1 NOT NULL;
+ALTER TABLE public.products ADD COLUMN version integer DEFAULT 1 NOT NULL;
+
+
+--
+-- Carts of the same customer, created by concurrent requests, are merged into the oldest one: their lines
+-- are added to it and their payments moved to it before they are deleted.
+--
+
+INSERT INTO public.cart_products (cart_id, product_id, quantity)
+SELECT k.id, cp.product_id, sum(cp.quantity)
+FROM public.cart_products cp
+JOIN public.carts c ON c.id = cp.cart_id
+JOIN (SELECT DISTINCT ON (full_name) full_name, id FROM public.carts ORDER BY full_name, created_at, id) k
+    ON k.full_name = c.full_name
+WHERE c.id <> k.id
+GROUP BY k.id, cp.product_id
+ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = public.cart_products.quantity + EXCLUDED.quantity;
+
+UPDATE public.payments p
+SET cart_id = k.id
+FROM public.carts c
+JOIN (SELECT DISTINCT ON (full_name) full_name, id FROM public.carts ORDER BY full_name, created_at, id) k
+    ON k.full_name = c.full_name
+WHERE p.cart_id = c.id AND c.id <> k.id;
+
+DELETE FROM public.carts c
+USING (SELECT DISTINCT ON (full_name) full_name, id FROM public.carts ORDER BY full_name, created_at, id) k
+WHERE k.full_name = c.full_name AND c.id <> k.id;
+
+DROP INDEX public.carts_full_name_idx;
+CREATE UNIQUE INDEX carts_full_name_key ON public.carts (full_name);
@@ -0,0 +1,7 @@
+ALTER TABLE public.discount_campaigns DROP COLUMN currency;
+
+ALTER TABLE public.refunds ALTER COLUMN amount TYPE numeric(21,2);
+ALTER TABLE public.payments ALTER COLUMN amount TYPE numeric(21,2);
+ALTER TABLE public.discount_campaigns ALTER COLUMN value TYPE numeric(21,2);
+ALTER TABLE public.products ALTER COLUMN discount_value TYPE numeric(21,2);
+ALTER TABLE public.products ALTER COLUMN price TYPE numeric(21,2);
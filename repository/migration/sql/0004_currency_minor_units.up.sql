@@ -0,0 +1,10 @@
+-- Amounts keep up to 3 decimal places, the minor units of KWD, and discount campaigns get the currency their
+-- fixed amounts are in.
+
+ALTER TABLE public.products ALTER COLUMN price TYPE numeric(21,3);
+ALTER TABLE public.products ALTER COLUMN discount_value TYPE numeric(21,3);
+ALTER TABLE public.discount_campaigns ALTER COLUMN value TYPE numeric(21,3);
+ALTER TABLE public.payments ALTER COLUMN amount TYPE numeric(21,3);
+ALTER TABLE public.refunds ALTER COLUMN amount TYPE numeric(21,3);
+
+ALTER TABLE public.discount_campaigns ADD COLUMN currency character(3) DEFAULT 'IDR'::bpchar NOT NULL;
@@ -0,0 +1,3 @@
+-- Keeps the priced cart each payment charges, payments made before have none.
+
+ALTER TABLE public.payments ADD COLUMN snapshot jsonb;
@@ -0,0 +1,7 @@
+ALTER TABLE public.refunds DROP COLUMN return_id;
+ALTER TABLE public.refunds DROP COLUMN items;
+ALTER TABLE public.refunds DROP COLUMN status;
+
+DROP TABLE IF EXISTS public.returns;
+
+ALTER TABLE public.products DROP COLUMN stock;
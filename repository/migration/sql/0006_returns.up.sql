@@ -0,0 +1,28 @@
+-- Adds product stock, the returns customers request on a payment, and the status of refunds, which are
+-- recorded as pending before the provider is asked for them.
+
+ALTER TABLE public.products ADD COLUMN stock integer DEFAULT 0 NOT NULL;
+
+CREATE TABLE public.returns (
+    id uuid NOT NULL,
+    payment_id uuid NOT NULL,
+    status character varying(20) NOT NULL,
+    reason text NOT NULL,
+    items jsonb NOT NULL,
+    created_at timestamp without time zone NOT NULL,
+    updated_at timestamp without time zone NOT NULL
+);
+
+ALTER TABLE public.returns ADD CONSTRAINT returns_pkey PRIMARY KEY (id);
+ALTER TABLE public.returns
+    ADD CONSTRAINT returns_payment_id_fkey FOREIGN KEY (payment_id) REFERENCES public.payments (id);
+
+CREATE INDEX returns_payment_id_idx ON public.returns (payment_id);
+
+ALTER TABLE public.refunds ADD COLUMN status character varying(20) DEFAULT 'succeeded' NOT NULL;
+ALTER TABLE public.refunds ADD COLUMN items jsonb DEFAULT '[]'::jsonb NOT NULL;
+ALTER TABLE public.refunds ADD COLUMN return_id uuid;
+ALTER TABLE public.refunds
+    ADD CONSTRAINT refunds_return_id_fkey FOREIGN KEY (return_id) REFERENCES public.returns (id);
+
+CREATE INDEX refunds_return_id_idx ON public.refunds (return_id);
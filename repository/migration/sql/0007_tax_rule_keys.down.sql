@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.tax_rules_default_key;
+DROP INDEX IF EXISTS public.tax_rules_category_key;
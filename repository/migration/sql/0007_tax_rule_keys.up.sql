@@ -0,0 +1,12 @@
+-- Makes the tax rule of a category, and the default rule without category, unique so that concurrent
+-- requests can't both create it.
+
+--
+-- Rules of the same category left by such requests are reduced to the one with the smallest id.
+--
+
+DELETE FROM public.tax_rules r USING public.tax_rules k
+WHERE k.category IS NOT DISTINCT FROM r.category AND k.id < r.id;
+
+CREATE UNIQUE INDEX tax_rules_category_key ON public.tax_rules (category);
+CREATE UNIQUE INDEX tax_rules_default_key ON public.tax_rules ((category IS NULL)) WHERE category IS NULL;
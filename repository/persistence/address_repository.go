@@ -0,0 +1,199 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AddressRepository interface {
+	WithTx(conn *sqlx.Tx) AddressRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.Address, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.Address, err error,
+	)
+	Store(ctx context.Context, data *entity.Address) (res entity.Address, err error)
+	Update(ctx context.Context, data *entity.Address) (res entity.Address, err error)
+	Delete(ctx context.Context, data *entity.Address) (err error)
+	UnsetDefault(ctx context.Context, fullName string) (err error)
+	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+}
+
+type addressRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewAddressRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) AddressRepository {
+	return &addressRepository{Conn: conn, TableName: "addresses", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r addressRepository) WithTx(conn *sqlx.Tx) AddressRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &addressRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r addressRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.Address, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r addressRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.Address, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r addressRepository) Store(ctx context.Context, data *entity.Address) (res entity.Address, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	data.GenerateUUID()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, full_name, label, recipient, phone, street, city, province, postal_code, country, "+
+			"is_default) VALUES (:id, :full_name, :label, :recipient, :phone, :street, :city, :province, :postal_code, "+
+			":country, :is_default)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+func (r addressRepository) Update(ctx context.Context, data *entity.Address) (res entity.Address, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET label=:label, recipient=:recipient, phone=:phone, street=:street, city=:city, "+
+			"province=:province, postal_code=:postal_code, country=:country, is_default=:is_default WHERE id=:id",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r addressRepository) Delete(ctx context.Context, data *entity.Address) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+// UnsetDefault clears the default flag of every address of fullName.
+func (r addressRepository) UnsetDefault(ctx context.Context, fullName string) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "UnsetDefault", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("UPDATE %s SET is_default = false WHERE full_name = $1 AND is_default", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, fullName)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+func (r addressRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return totalRow, err
+	}
+
+	queryFrom, args, err := sq.ToSql()
+	if err != nil {
+		return totalRow, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
+	if err != nil {
+		return totalRow, err
+	}
+
+	return totalRow, nil
+}
@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"interview-telkom-6/entity"
-	"log"
+	"interview-telkom-6/logger"
+	"interview-telkom-6/util"
+	"log/slog"
+	"time"
 )
 
 type cartProductRepo struct {
 	Conn      Queryer
 	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
 }
 
 type CartProductRepository interface {
@@ -31,40 +37,39 @@ type CartProductRepository interface {
 	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
 }
 
-func NewCartProductRepo(conn *sqlx.DB) CartProductRepository {
-	return &cartProductRepo{Conn: conn, TableName: "cart_products"}
+func NewCartProductRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) CartProductRepository {
+	return &cartProductRepo{Conn: conn, TableName: "cart_products", Log: log, QueryTimeout: queryTimeout}
 }
 
 func (r cartProductRepo) WithTx(conn *sqlx.Tx) CartProductRepository {
 	if conn == nil {
-		log.Println("transaction database not found")
+		r.Log.Warn("transaction database not found", "table", r.TableName)
 		return &r
 	}
 
-	return &cartProductRepo{Conn: conn}
+	return &cartProductRepo{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
 }
 
 func (r cartProductRepo) Get(ctx context.Context, builder *QueryBuilderCriteria) (
 	res entity.CartProduct, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Get(&res, query, args...)
+	err = r.Conn.GetContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -74,24 +79,23 @@ func (r cartProductRepo) Get(ctx context.Context, builder *QueryBuilderCriteria)
 func (r cartProductRepo) Find(ctx context.Context, builder *QueryBuilderCriteria) (
 	res []entity.CartProduct, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Select(&res, query, args...)
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -101,78 +105,89 @@ func (r cartProductRepo) Find(ctx context.Context, builder *QueryBuilderCriteria
 func (r cartProductRepo) Store(ctx context.Context, data *entity.CartProduct) (
 	res entity.CartProduct, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	query := fmt.Sprintf(
 		"INSERT INTO cart_products (cart_id, product_id, quantity) VALUES (:cart_id, :product_id, :quantity)",
 	)
-	log.Println(query)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
 	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if isUniqueViolation(err) {
+		return res, &util.ConflictError{Message: "the product was added to the cart by another request"}
+	}
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	data.Version = 1
 
 	return *data, err
 }
 
+// Update only applies to the version data was read at, a ConflictError telling the line changed since.
 func (r cartProductRepo) Update(ctx context.Context, data *entity.CartProduct) (
 	res entity.CartProduct, err error,
 ) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf(
-		"UPDATE cart_products SET product_id=:product_id, quantity=:quantity WHERE cart_id=:cart_id",
+		"UPDATE cart_products SET quantity=:quantity, version=version+1 " +
+			"WHERE cart_id=:cart_id AND product_id=:product_id AND version=:version",
 	)
-	log.Println(query)
-	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	rows, _ = result.RowsAffected()
+	if rows == 0 {
+		return res, &util.ConflictError{Message: "the cart line was changed by another request"}
+	}
+	data.Version++
 
 	return *data, nil
 }
 
 func (r cartProductRepo) Delete(ctx context.Context, data *entity.CartProduct) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf("DELETE FROM cart_products WHERE product_id = $1")
-	log.Println(query)
-	_, err = r.Conn.Exec(query, data.ProductID)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ProductID)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
+	rows, _ = result.RowsAffected()
 
 	return nil
 }
 
 func (r cartProductRepo) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	queryFrom, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	rows, err := r.Conn.Queryx(query, args...)
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
-	for rows.Next() {
-		err = rows.Scan(&totalRow)
-		if err != nil {
-			log.Println(err)
-			return totalRow, err
-		}
-	}
-
 	return totalRow, nil
 }
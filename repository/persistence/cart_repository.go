@@ -5,12 +5,18 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"interview-telkom-6/entity"
-	"log"
+	"interview-telkom-6/logger"
+	"interview-telkom-6/util"
+	"log/slog"
+	"time"
 )
 
 type cartRepository struct {
 	Conn      Queryer
 	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
 }
 
 type CartRepository interface {
@@ -24,43 +30,43 @@ type CartRepository interface {
 	Store(ctx context.Context, data *entity.Cart) (res entity.Cart, err error)
 	Update(ctx context.Context, data *entity.Cart) (res entity.Cart, err error)
 	Delete(ctx context.Context, data *entity.Cart) (err error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (deleted int64, err error)
 	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
 }
 
-func NewCartRepository(conn *sqlx.DB) CartRepository {
-	return &cartRepository{Conn: conn, TableName: "carts"}
+func NewCartRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) CartRepository {
+	return &cartRepository{Conn: conn, TableName: "carts", Log: log, QueryTimeout: queryTimeout}
 }
 
 func (r cartRepository) WithTx(conn *sqlx.Tx) CartRepository {
 	if conn == nil {
-		log.Println("transaction database not found")
+		r.Log.Warn("transaction database not found", "table", r.TableName)
 		return &r
 	}
 
-	return &cartRepository{Conn: conn}
+	return &cartRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
 }
 
 func (r cartRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
 	res entity.Cart, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Get(&res, query, args...)
+	err = r.Conn.GetContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -70,24 +76,23 @@ func (r cartRepository) Get(ctx context.Context, builder *QueryBuilderCriteria)
 func (r cartRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
 	res []entity.Cart, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Select(&res, query, args...)
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -95,77 +100,104 @@ func (r cartRepository) Find(ctx context.Context, builder *QueryBuilderCriteria)
 }
 
 func (r cartRepository) Store(ctx context.Context, data *entity.Cart) (res entity.Cart, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	data.GenerateUUID()
 	query := fmt.Sprintf(
-		"INSERT INTO carts (id, full_name) VALUES (:id, :full_name)",
+		"INSERT INTO carts (id, full_name, created_at) VALUES (:id, :full_name, :created_at)",
 	)
-	log.Println(query)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
 	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if isUniqueViolation(err) {
+		return res, &util.ConflictError{Message: "the cart was created by another request"}
+	}
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	data.Version = 1
 
 	return *data, err
 }
 
+// Update only applies to the version data was read at, a ConflictError telling the cart changed since.
 func (r cartRepository) Update(ctx context.Context, data *entity.Cart) (res entity.Cart, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf(
-		"UPDATE carts SET full_name=:full_name WHERE id=:id",
+		"UPDATE carts SET full_name=:full_name, version=version+1 WHERE id=:id AND version=:version",
 	)
-	log.Println(query)
-	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	rows, _ = result.RowsAffected()
+	if rows == 0 {
+		return res, &util.ConflictError{Message: "the cart was changed by another request"}
+	}
+	data.Version++
 
 	return *data, nil
 }
 
 func (r cartRepository) Delete(ctx context.Context, data *entity.Cart) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf("DELETE FROM carts WHERE id = $1")
-	log.Println(query)
-	_, err = r.Conn.Exec(query, data.ID)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
+	rows, _ = result.RowsAffected()
 
 	return nil
 }
 
+// DeleteCreatedBefore deletes the carts created before before, with their lines, except the ones having a
+// payment.
+func (r cartRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (deleted int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "DeleteCreatedBefore", r.QueryTimeout)
+	defer func() { err = end(deleted, err) }()
+
+	query := "DELETE FROM carts WHERE created_at < $1 AND NOT EXISTS " +
+		"(SELECT 1 FROM payments WHERE payments.cart_id = carts.id)"
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (r cartRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	queryFrom, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	rows, err := r.Conn.Queryx(query, args...)
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
-	for rows.Next() {
-		err = rows.Scan(&totalRow)
-		if err != nil {
-			log.Println(err)
-			return totalRow, err
-		}
-	}
-
 	return totalRow, nil
 }
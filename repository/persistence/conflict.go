@@ -0,0 +1,14 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// isUniqueViolation tells whether err is Postgres refusing a row whose key is already taken, which for rows
+// inserted after checking they didn't exist means another request inserted it in between.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
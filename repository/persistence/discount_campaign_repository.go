@@ -0,0 +1,180 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DiscountCampaignRepository interface {
+	WithTx(conn *sqlx.Tx) DiscountCampaignRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.DiscountCampaign, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.DiscountCampaign, err error,
+	)
+	Store(ctx context.Context, data *entity.DiscountCampaign) (res entity.DiscountCampaign, err error)
+	Update(ctx context.Context, data *entity.DiscountCampaign) (res entity.DiscountCampaign, err error)
+	Delete(ctx context.Context, data *entity.DiscountCampaign) (err error)
+	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+}
+
+type discountCampaignRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewDiscountCampaignRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) DiscountCampaignRepository {
+	return &discountCampaignRepository{Conn: conn, TableName: "discount_campaigns", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r discountCampaignRepository) WithTx(conn *sqlx.Tx) DiscountCampaignRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &discountCampaignRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r discountCampaignRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.DiscountCampaign, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r discountCampaignRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.DiscountCampaign, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r discountCampaignRepository) Store(ctx context.Context, data *entity.DiscountCampaign) (res entity.DiscountCampaign, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	data.GenerateUUID()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, name, discount_type, value, currency, priority, is_stackable, start_date, end_date) "+
+			"VALUES (:id, :name, :discount_type, :value, :currency, :priority, :is_stackable, :start_date, :end_date)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+func (r discountCampaignRepository) Update(ctx context.Context, data *entity.DiscountCampaign) (res entity.DiscountCampaign, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET name=:name, discount_type=:discount_type, value=:value, currency=:currency, priority=:priority, "+
+			"is_stackable=:is_stackable, start_date=:start_date, end_date=:end_date WHERE id=:id",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r discountCampaignRepository) Delete(ctx context.Context, data *entity.DiscountCampaign) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+func (r discountCampaignRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return totalRow, err
+	}
+
+	queryFrom, args, err := sq.ToSql()
+	if err != nil {
+		return totalRow, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
+	if err != nil {
+		return totalRow, err
+	}
+
+	return totalRow, nil
+}
@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DiscountCampaignTargetRepository interface {
+	WithTx(conn *sqlx.Tx) DiscountCampaignTargetRepository
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.DiscountCampaignTarget, err error,
+	)
+	Store(ctx context.Context, data *entity.DiscountCampaignTarget) (
+		res entity.DiscountCampaignTarget, err error,
+	)
+	DeleteByCampaign(ctx context.Context, data *entity.DiscountCampaign) (err error)
+}
+
+type discountCampaignTargetRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewDiscountCampaignTargetRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) DiscountCampaignTargetRepository {
+	return &discountCampaignTargetRepository{Conn: conn, TableName: "discount_campaign_targets", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r discountCampaignTargetRepository) WithTx(conn *sqlx.Tx) DiscountCampaignTargetRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &discountCampaignTargetRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r discountCampaignTargetRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.DiscountCampaignTarget, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r discountCampaignTargetRepository) Store(ctx context.Context, data *entity.DiscountCampaignTarget) (
+	res entity.DiscountCampaignTarget, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (campaign_id, product_id, category) VALUES (:campaign_id, :product_id, :category)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, nil
+}
+
+func (r discountCampaignTargetRepository) DeleteByCampaign(ctx context.Context, data *entity.DiscountCampaign) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "DeleteByCampaign", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE campaign_id = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
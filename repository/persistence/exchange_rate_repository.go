@@ -0,0 +1,179 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ExchangeRateRepository interface {
+	WithTx(conn *sqlx.Tx) ExchangeRateRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.ExchangeRate, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.ExchangeRate, err error,
+	)
+	Store(ctx context.Context, data *entity.ExchangeRate) (res entity.ExchangeRate, err error)
+	Update(ctx context.Context, data *entity.ExchangeRate) (res entity.ExchangeRate, err error)
+	Delete(ctx context.Context, data *entity.ExchangeRate) (err error)
+	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+}
+
+type exchangeRateRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewExchangeRateRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) ExchangeRateRepository {
+	return &exchangeRateRepository{Conn: conn, TableName: "exchange_rates", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r exchangeRateRepository) WithTx(conn *sqlx.Tx) ExchangeRateRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &exchangeRateRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r exchangeRateRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.ExchangeRate, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r exchangeRateRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.ExchangeRate, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r exchangeRateRepository) Store(ctx context.Context, data *entity.ExchangeRate) (res entity.ExchangeRate, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (base_currency, quote_currency, rate, updated_at) "+
+			"VALUES (:base_currency, :quote_currency, :rate, :updated_at)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+func (r exchangeRateRepository) Update(ctx context.Context, data *entity.ExchangeRate) (res entity.ExchangeRate, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET rate=:rate, updated_at=:updated_at "+
+			"WHERE base_currency=:base_currency AND quote_currency=:quote_currency",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r exchangeRateRepository) Delete(ctx context.Context, data *entity.ExchangeRate) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE base_currency = $1 AND quote_currency = $2", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.BaseCurrency, data.QuoteCurrency)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+func (r exchangeRateRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return totalRow, err
+	}
+
+	queryFrom, args, err := sq.ToSql()
+	if err != nil {
+		return totalRow, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
+	if err != nil {
+		return totalRow, err
+	}
+
+	return totalRow, nil
+}
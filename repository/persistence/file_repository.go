@@ -0,0 +1,178 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type FileRepository interface {
+	WithTx(conn *sqlx.Tx) FileRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.File, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.File, err error,
+	)
+	Store(ctx context.Context, data *entity.File) (res entity.File, err error)
+	Update(ctx context.Context, data *entity.File) (res entity.File, err error)
+	Delete(ctx context.Context, data *entity.File) (err error)
+	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+}
+
+type fileRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewFileRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) FileRepository {
+	return &fileRepository{Conn: conn, TableName: "files", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r fileRepository) WithTx(conn *sqlx.Tx) FileRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &fileRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r fileRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.File, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r fileRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.File, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r fileRepository) Store(ctx context.Context, data *entity.File) (res entity.File, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	data.GenerateUUID()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, name, location, bucket_name) VALUES (:id, :name, :location, :bucket_name)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+func (r fileRepository) Update(ctx context.Context, data *entity.File) (res entity.File, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET name=:name, location=:location, bucket_name=:bucket_name WHERE id=:id",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r fileRepository) Delete(ctx context.Context, data *entity.File) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+func (r fileRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return totalRow, err
+	}
+
+	queryFrom, args, err := sq.ToSql()
+	if err != nil {
+		return totalRow, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
+	if err != nil {
+		return totalRow, err
+	}
+
+	return totalRow, nil
+}
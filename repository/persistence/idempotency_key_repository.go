@@ -0,0 +1,172 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyKeyRepository interface {
+	WithTx(conn *sqlx.Tx) IdempotencyKeyRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.IdempotencyKey, err error,
+	)
+	StoreIfAbsent(ctx context.Context, data *entity.IdempotencyKey) (stored bool, err error)
+	Reclaim(ctx context.Context, data *entity.IdempotencyKey, before time.Time) (reclaimed bool, err error)
+	Update(ctx context.Context, data *entity.IdempotencyKey) (res entity.IdempotencyKey, err error)
+	Delete(ctx context.Context, data *entity.IdempotencyKey) (err error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (deleted int64, err error)
+}
+
+type idempotencyKeyRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewIdempotencyKeyRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{Conn: conn, TableName: "idempotency_keys", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r idempotencyKeyRepository) WithTx(conn *sqlx.Tx) IdempotencyKeyRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &idempotencyKeyRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r idempotencyKeyRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.IdempotencyKey, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// StoreIfAbsent records data unless its key is already there, which is how concurrent requests sharing a key
+// agree on the one to serve. stored tells whether data was recorded.
+func (r idempotencyKeyRepository) StoreIfAbsent(ctx context.Context, data *entity.IdempotencyKey) (
+	stored bool, err error,
+) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "StoreIfAbsent", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (key, request_hash) VALUES (:key, :request_hash) ON CONFLICT (key) DO NOTHING",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return false, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return rows > 0, nil
+}
+
+// Reclaim claims data's key again for the same request when it was claimed before before and no response was
+// recorded since, the request holding it having most likely died. reclaimed tells whether the key was claimed,
+// only one of concurrent retries doing so.
+func (r idempotencyKeyRepository) Reclaim(ctx context.Context, data *entity.IdempotencyKey, before time.Time) (
+	reclaimed bool, err error,
+) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Reclaim", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET created_at = now() WHERE key = $1 AND request_hash = $2 AND status IS NULL "+
+			"AND created_at < $3",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.Key, data.RequestHash, before)
+	if err != nil {
+		return false, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return rows > 0, nil
+}
+
+func (r idempotencyKeyRepository) Update(ctx context.Context, data *entity.IdempotencyKey) (
+	res entity.IdempotencyKey, err error,
+) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET status=:status, content_type=:content_type, body=:body WHERE key=:key", r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r idempotencyKeyRepository) Delete(ctx context.Context, data *entity.IdempotencyKey) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE key = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.Key)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+// DeleteCreatedBefore deletes the keys recorded before before, their requests can't be replayed anymore.
+func (r idempotencyKeyRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (
+	deleted int64, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "DeleteCreatedBefore", r.QueryTimeout)
+	defer func() { err = end(deleted, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
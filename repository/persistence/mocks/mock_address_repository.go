@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: address_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "interview-telkom-6/entity"
+	persistence "interview-telkom-6/repository/persistence"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+)
+
+// MockAddressRepository is a mock of AddressRepository interface.
+type MockAddressRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAddressRepositoryMockRecorder
+}
+
+// MockAddressRepositoryMockRecorder is the mock recorder for MockAddressRepository.
+type MockAddressRepositoryMockRecorder struct {
+	mock *MockAddressRepository
+}
+
+// NewMockAddressRepository creates a new mock instance.
+func NewMockAddressRepository(ctrl *gomock.Controller) *MockAddressRepository {
+	mock := &MockAddressRepository{ctrl: ctrl}
+	mock.recorder = &MockAddressRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAddressRepository) EXPECT() *MockAddressRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockAddressRepository) Count(ctx context.Context, builder *persistence.QueryBuilderCriteria) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, builder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockAddressRepositoryMockRecorder) Count(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockAddressRepository)(nil).Count), ctx, builder)
+}
+
+// Delete mocks base method.
+func (m *MockAddressRepository) Delete(ctx context.Context, data *entity.Address) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAddressRepositoryMockRecorder) Delete(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAddressRepository)(nil).Delete), ctx, data)
+}
+
+// Find mocks base method.
+func (m *MockAddressRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, builder)
+	ret0, _ := ret[0].([]entity.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockAddressRepositoryMockRecorder) Find(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAddressRepository)(nil).Find), ctx, builder)
+}
+
+// Get mocks base method.
+func (m *MockAddressRepository) Get(ctx context.Context, builder *persistence.QueryBuilderCriteria) (entity.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, builder)
+	ret0, _ := ret[0].(entity.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAddressRepositoryMockRecorder) Get(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAddressRepository)(nil).Get), ctx, builder)
+}
+
+// Store mocks base method.
+func (m *MockAddressRepository) Store(ctx context.Context, data *entity.Address) (entity.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, data)
+	ret0, _ := ret[0].(entity.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockAddressRepositoryMockRecorder) Store(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockAddressRepository)(nil).Store), ctx, data)
+}
+
+// UnsetDefault mocks base method.
+func (m *MockAddressRepository) UnsetDefault(ctx context.Context, fullName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsetDefault", ctx, fullName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsetDefault indicates an expected call of UnsetDefault.
+func (mr *MockAddressRepositoryMockRecorder) UnsetDefault(ctx, fullName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetDefault", reflect.TypeOf((*MockAddressRepository)(nil).UnsetDefault), ctx, fullName)
+}
+
+// Update mocks base method.
+func (m *MockAddressRepository) Update(ctx context.Context, data *entity.Address) (entity.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, data)
+	ret0, _ := ret[0].(entity.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockAddressRepositoryMockRecorder) Update(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAddressRepository)(nil).Update), ctx, data)
+}
+
+// WithTx mocks base method.
+func (m *MockAddressRepository) WithTx(conn *sqlx.Tx) persistence.AddressRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", conn)
+	ret0, _ := ret[0].(persistence.AddressRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockAddressRepositoryMockRecorder) WithTx(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockAddressRepository)(nil).WithTx), conn)
+}
@@ -9,6 +9,7 @@ import (
 	entity "interview-telkom-6/entity"
 	persistence "interview-telkom-6/repository/persistence"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	sqlx "github.com/jmoiron/sqlx"
@@ -66,6 +67,21 @@ func (mr *MockCartRepositoryMockRecorder) Delete(ctx, data interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCartRepository)(nil).Delete), ctx, data)
 }
 
+// DeleteCreatedBefore mocks base method.
+func (m *MockCartRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCreatedBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCreatedBefore indicates an expected call of DeleteCreatedBefore.
+func (mr *MockCartRepositoryMockRecorder) DeleteCreatedBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCreatedBefore", reflect.TypeOf((*MockCartRepository)(nil).DeleteCreatedBefore), ctx, before)
+}
+
 // Find mocks base method.
 func (m *MockCartRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.Cart, error) {
 	m.ctrl.T.Helper()
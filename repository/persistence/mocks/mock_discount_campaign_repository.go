@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: discount_campaign_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "interview-telkom-6/entity"
+	persistence "interview-telkom-6/repository/persistence"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+)
+
+// MockDiscountCampaignRepository is a mock of DiscountCampaignRepository interface.
+type MockDiscountCampaignRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiscountCampaignRepositoryMockRecorder
+}
+
+// MockDiscountCampaignRepositoryMockRecorder is the mock recorder for MockDiscountCampaignRepository.
+type MockDiscountCampaignRepositoryMockRecorder struct {
+	mock *MockDiscountCampaignRepository
+}
+
+// NewMockDiscountCampaignRepository creates a new mock instance.
+func NewMockDiscountCampaignRepository(ctrl *gomock.Controller) *MockDiscountCampaignRepository {
+	mock := &MockDiscountCampaignRepository{ctrl: ctrl}
+	mock.recorder = &MockDiscountCampaignRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiscountCampaignRepository) EXPECT() *MockDiscountCampaignRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockDiscountCampaignRepository) Count(ctx context.Context, builder *persistence.QueryBuilderCriteria) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, builder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Count(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Count), ctx, builder)
+}
+
+// Delete mocks base method.
+func (m *MockDiscountCampaignRepository) Delete(ctx context.Context, data *entity.DiscountCampaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Delete(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Delete), ctx, data)
+}
+
+// Find mocks base method.
+func (m *MockDiscountCampaignRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.DiscountCampaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, builder)
+	ret0, _ := ret[0].([]entity.DiscountCampaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Find(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Find), ctx, builder)
+}
+
+// Get mocks base method.
+func (m *MockDiscountCampaignRepository) Get(ctx context.Context, builder *persistence.QueryBuilderCriteria) (entity.DiscountCampaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, builder)
+	ret0, _ := ret[0].(entity.DiscountCampaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Get(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Get), ctx, builder)
+}
+
+// Store mocks base method.
+func (m *MockDiscountCampaignRepository) Store(ctx context.Context, data *entity.DiscountCampaign) (entity.DiscountCampaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, data)
+	ret0, _ := ret[0].(entity.DiscountCampaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Store(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Store), ctx, data)
+}
+
+// Update mocks base method.
+func (m *MockDiscountCampaignRepository) Update(ctx context.Context, data *entity.DiscountCampaign) (entity.DiscountCampaign, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, data)
+	ret0, _ := ret[0].(entity.DiscountCampaign)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) Update(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).Update), ctx, data)
+}
+
+// WithTx mocks base method.
+func (m *MockDiscountCampaignRepository) WithTx(conn *sqlx.Tx) persistence.DiscountCampaignRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", conn)
+	ret0, _ := ret[0].(persistence.DiscountCampaignRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDiscountCampaignRepositoryMockRecorder) WithTx(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDiscountCampaignRepository)(nil).WithTx), conn)
+}
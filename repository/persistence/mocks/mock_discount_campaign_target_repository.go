@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: discount_campaign_target_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "interview-telkom-6/entity"
+	persistence "interview-telkom-6/repository/persistence"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+)
+
+// MockDiscountCampaignTargetRepository is a mock of DiscountCampaignTargetRepository interface.
+type MockDiscountCampaignTargetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiscountCampaignTargetRepositoryMockRecorder
+}
+
+// MockDiscountCampaignTargetRepositoryMockRecorder is the mock recorder for MockDiscountCampaignTargetRepository.
+type MockDiscountCampaignTargetRepositoryMockRecorder struct {
+	mock *MockDiscountCampaignTargetRepository
+}
+
+// NewMockDiscountCampaignTargetRepository creates a new mock instance.
+func NewMockDiscountCampaignTargetRepository(ctrl *gomock.Controller) *MockDiscountCampaignTargetRepository {
+	mock := &MockDiscountCampaignTargetRepository{ctrl: ctrl}
+	mock.recorder = &MockDiscountCampaignTargetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiscountCampaignTargetRepository) EXPECT() *MockDiscountCampaignTargetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteByCampaign mocks base method.
+func (m *MockDiscountCampaignTargetRepository) DeleteByCampaign(ctx context.Context, data *entity.DiscountCampaign) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByCampaign", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByCampaign indicates an expected call of DeleteByCampaign.
+func (mr *MockDiscountCampaignTargetRepositoryMockRecorder) DeleteByCampaign(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByCampaign", reflect.TypeOf((*MockDiscountCampaignTargetRepository)(nil).DeleteByCampaign), ctx, data)
+}
+
+// Find mocks base method.
+func (m *MockDiscountCampaignTargetRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.DiscountCampaignTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, builder)
+	ret0, _ := ret[0].([]entity.DiscountCampaignTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockDiscountCampaignTargetRepositoryMockRecorder) Find(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockDiscountCampaignTargetRepository)(nil).Find), ctx, builder)
+}
+
+// Store mocks base method.
+func (m *MockDiscountCampaignTargetRepository) Store(ctx context.Context, data *entity.DiscountCampaignTarget) (entity.DiscountCampaignTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, data)
+	ret0, _ := ret[0].(entity.DiscountCampaignTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockDiscountCampaignTargetRepositoryMockRecorder) Store(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockDiscountCampaignTargetRepository)(nil).Store), ctx, data)
+}
+
+// WithTx mocks base method.
+func (m *MockDiscountCampaignTargetRepository) WithTx(conn *sqlx.Tx) persistence.DiscountCampaignTargetRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", conn)
+	ret0, _ := ret[0].(persistence.DiscountCampaignTargetRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDiscountCampaignTargetRepositoryMockRecorder) WithTx(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDiscountCampaignTargetRepository)(nil).WithTx), conn)
+}
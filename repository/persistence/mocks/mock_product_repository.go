@@ -11,6 +11,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	sqlx "github.com/jmoiron/sqlx"
 )
 
@@ -37,6 +38,21 @@ func (m *MockProductRepository) EXPECT() *MockProductRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddStock mocks base method.
+func (m *MockProductRepository) AddStock(ctx context.Context, id uuid.UUID, quantity int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStock", ctx, id, quantity)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStock indicates an expected call of AddStock.
+func (mr *MockProductRepositoryMockRecorder) AddStock(ctx, id, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStock", reflect.TypeOf((*MockProductRepository)(nil).AddStock), ctx, id, quantity)
+}
+
 // Count mocks base method.
 func (m *MockProductRepository) Count(ctx context.Context, builder *persistence.QueryBuilderCriteria) (int64, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +82,18 @@ func (mr *MockProductRepositoryMockRecorder) Delete(ctx, data interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProductRepository)(nil).Delete), ctx, data)
 }
 
+// EndTx mocks base method.
+func (m *MockProductRepository) EndTx(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EndTx", ctx)
+}
+
+// EndTx indicates an expected call of EndTx.
+func (mr *MockProductRepositoryMockRecorder) EndTx(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndTx", reflect.TypeOf((*MockProductRepository)(nil).EndTx), ctx)
+}
+
 // Find mocks base method.
 func (m *MockProductRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.Product, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +109,21 @@ func (mr *MockProductRepositoryMockRecorder) Find(ctx, builder interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockProductRepository)(nil).Find), ctx, builder)
 }
 
+// FindByIDs mocks base method.
+func (m *MockProductRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDs", ctx, ids)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDs indicates an expected call of FindByIDs.
+func (mr *MockProductRepositoryMockRecorder) FindByIDs(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDs", reflect.TypeOf((*MockProductRepository)(nil).FindByIDs), ctx, ids)
+}
+
 // Get mocks base method.
 func (m *MockProductRepository) Get(ctx context.Context, builder *persistence.QueryBuilderCriteria) (entity.Product, error) {
 	m.ctrl.T.Helper()
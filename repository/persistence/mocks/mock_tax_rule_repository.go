@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tax_rule_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	entity "interview-telkom-6/entity"
+	persistence "interview-telkom-6/repository/persistence"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+)
+
+// MockTaxRuleRepository is a mock of TaxRuleRepository interface.
+type MockTaxRuleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxRuleRepositoryMockRecorder
+}
+
+// MockTaxRuleRepositoryMockRecorder is the mock recorder for MockTaxRuleRepository.
+type MockTaxRuleRepositoryMockRecorder struct {
+	mock *MockTaxRuleRepository
+}
+
+// NewMockTaxRuleRepository creates a new mock instance.
+func NewMockTaxRuleRepository(ctrl *gomock.Controller) *MockTaxRuleRepository {
+	mock := &MockTaxRuleRepository{ctrl: ctrl}
+	mock.recorder = &MockTaxRuleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaxRuleRepository) EXPECT() *MockTaxRuleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockTaxRuleRepository) Count(ctx context.Context, builder *persistence.QueryBuilderCriteria) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, builder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockTaxRuleRepositoryMockRecorder) Count(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockTaxRuleRepository)(nil).Count), ctx, builder)
+}
+
+// Delete mocks base method.
+func (m *MockTaxRuleRepository) Delete(ctx context.Context, data *entity.TaxRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTaxRuleRepositoryMockRecorder) Delete(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaxRuleRepository)(nil).Delete), ctx, data)
+}
+
+// Find mocks base method.
+func (m *MockTaxRuleRepository) Find(ctx context.Context, builder *persistence.QueryBuilderCriteria) ([]entity.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, builder)
+	ret0, _ := ret[0].([]entity.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockTaxRuleRepositoryMockRecorder) Find(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockTaxRuleRepository)(nil).Find), ctx, builder)
+}
+
+// Get mocks base method.
+func (m *MockTaxRuleRepository) Get(ctx context.Context, builder *persistence.QueryBuilderCriteria) (entity.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, builder)
+	ret0, _ := ret[0].(entity.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockTaxRuleRepositoryMockRecorder) Get(ctx, builder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTaxRuleRepository)(nil).Get), ctx, builder)
+}
+
+// Store mocks base method.
+func (m *MockTaxRuleRepository) Store(ctx context.Context, data *entity.TaxRule) (entity.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, data)
+	ret0, _ := ret[0].(entity.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockTaxRuleRepositoryMockRecorder) Store(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockTaxRuleRepository)(nil).Store), ctx, data)
+}
+
+// Update mocks base method.
+func (m *MockTaxRuleRepository) Update(ctx context.Context, data *entity.TaxRule) (entity.TaxRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, data)
+	ret0, _ := ret[0].(entity.TaxRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTaxRuleRepositoryMockRecorder) Update(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaxRuleRepository)(nil).Update), ctx, data)
+}
+
+// WithTx mocks base method.
+func (m *MockTaxRuleRepository) WithTx(conn *sqlx.Tx) persistence.TaxRuleRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", conn)
+	ret0, _ := ret[0].(persistence.TaxRuleRepository)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockTaxRuleRepositoryMockRecorder) WithTx(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockTaxRuleRepository)(nil).WithTx), conn)
+}
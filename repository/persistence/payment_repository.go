@@ -0,0 +1,224 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PaymentRepository interface {
+	WithTx(conn *sqlx.Tx) PaymentRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.Payment, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.Payment, err error,
+	)
+	Store(ctx context.Context, data *entity.Payment) (res entity.Payment, err error)
+	Update(ctx context.Context, data *entity.Payment) (res entity.Payment, err error)
+	Delete(ctx context.Context, data *entity.Payment) (err error)
+	UpdateStatus(ctx context.Context, data *entity.Payment, from string) (updated bool, err error)
+	GetForUpdate(ctx context.Context, id uuid.UUID) (res entity.Payment, err error)
+	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+}
+
+type paymentRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewPaymentRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) PaymentRepository {
+	return &paymentRepository{Conn: conn, TableName: "payments", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r paymentRepository) WithTx(conn *sqlx.Tx) PaymentRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &paymentRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r paymentRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.Payment, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r paymentRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.Payment, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r paymentRepository) Store(ctx context.Context, data *entity.Payment) (res entity.Payment, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	data.GenerateUUID()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, cart_id, provider, provider_ref, amount, currency, status, snapshot, created_at, "+
+			"updated_at) VALUES (:id, :cart_id, :provider, :provider_ref, :amount, :currency, :status, :snapshot, "+
+			":created_at, :updated_at)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+func (r paymentRepository) Update(ctx context.Context, data *entity.Payment) (res entity.Payment, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET provider_ref=:provider_ref, amount=:amount, currency=:currency, status=:status, "+
+			"updated_at=:updated_at WHERE id=:id",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+	rows, _ = result.RowsAffected()
+
+	return *data, nil
+}
+
+func (r paymentRepository) Delete(ctx context.Context, data *entity.Payment) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
+	if err != nil {
+		return err
+	}
+	rows, _ = result.RowsAffected()
+
+	return nil
+}
+
+// UpdateStatus moves the payment to data.Status only while it is still in status from, updated is false when
+// another request changed it first.
+func (r paymentRepository) UpdateStatus(ctx context.Context, data *entity.Payment, from string) (
+	updated bool, err error,
+) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "UpdateStatus", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.Status, data.UpdatedAt, data.ID, from)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// GetForUpdate returns the payment id and locks its row until the end of the transaction of the repository, so
+// that refunds and returns of the payment are made one after the other.
+func (r paymentRepository) GetForUpdate(ctx context.Context, id uuid.UUID) (res entity.Payment, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "GetForUpdate", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 FOR UPDATE", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	err = r.Conn.GetContext(ctx, &res, query, id)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r paymentRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return totalRow, err
+	}
+
+	queryFrom, args, err := sq.ToSql()
+	if err != nil {
+		return totalRow, err
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
+	if err != nil {
+		return totalRow, err
+	}
+
+	return totalRow, nil
+}
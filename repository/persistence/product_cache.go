@@ -0,0 +1,255 @@
+package persistence
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"interview-telkom-6/cache"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/metrics"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// productCacheName labels the metrics of the product cache and prefixes its keys.
+const productCacheName = "products"
+
+// cachedProductRepository serves the product reads of repo from cache, going to repo on a miss and keeping
+// its result. Every key embeds a generation that writes replace, which invalidates every entry at once:
+// listings, counts and products by id alike, as a write may change any of them. As the generation is read
+// before the database, a read racing a write stores its result under the outdated generation, where no one
+// looks for it.
+type cachedProductRepository struct {
+	repo  ProductRepository
+	cache cache.Cache
+	Log   *slog.Logger
+	// inTx bypasses the cache for reads, which may see uncommitted rows.
+	inTx bool
+	// written tells the transaction wrote products, the cache being invalidated once it ends.
+	written *bool
+}
+
+// NewCachedProductRepository decorates repo with c. With several instances of the application sharing the
+// database but not c, an instance only sees the writes of another once its entries expire.
+func NewCachedProductRepository(repo ProductRepository, c cache.Cache, log *slog.Logger) ProductRepository {
+	return &cachedProductRepository{repo: repo, cache: c, Log: log}
+}
+
+// WithTx returns a repository that reads from the transaction, never from cache. Its writes invalidate the
+// cache in EndTx rather than right away: a read between a write and the commit caches the previous rows, which
+// the commit must make outdated. The cache is invalidated on rollback too, a commit that failed may have been
+// applied all the same.
+func (r cachedProductRepository) WithTx(conn *sqlx.Tx) ProductRepository {
+	return &cachedProductRepository{
+		repo: r.repo.WithTx(conn), cache: r.cache, Log: r.Log, inTx: true, written: new(bool),
+	}
+}
+
+func (r cachedProductRepository) EndTx(ctx context.Context) {
+	r.repo.EndTx(ctx)
+	if r.written != nil && *r.written {
+		r.invalidate(ctx)
+	}
+}
+
+func (r cachedProductRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.Product, err error,
+) {
+	key, ok := r.queryKey(ctx, "get", builder)
+	if !ok {
+		return r.repo.Get(ctx, builder)
+	}
+	if r.load(ctx, key, &res) {
+		return res, nil
+	}
+
+	res, err = r.repo.Get(ctx, builder)
+	if err != nil {
+		return res, err
+	}
+	r.store(ctx, key, res)
+
+	return res, nil
+}
+
+func (r cachedProductRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.Product, err error,
+) {
+	key, ok := r.queryKey(ctx, "find", builder)
+	if !ok {
+		return r.repo.Find(ctx, builder)
+	}
+	if r.load(ctx, key, &res) {
+		return res, nil
+	}
+
+	res, err = r.repo.Find(ctx, builder)
+	if err != nil {
+		return res, err
+	}
+	r.store(ctx, key, res)
+
+	return res, nil
+}
+
+// FindByIDs keeps the products one by one, so that carts sharing products share their entries, and only asks
+// repo for the missing ones.
+func (r cachedProductRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) (res []entity.Product, err error) {
+	if r.inTx {
+		return r.repo.FindByIDs(ctx, ids)
+	}
+
+	generation := r.generation(ctx)
+	missing := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		var product entity.Product
+		if r.load(ctx, idKey(generation, id), &product) {
+			res = append(res, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	found, err := r.repo.FindByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range found {
+		r.store(ctx, idKey(generation, product.ID), product)
+	}
+
+	return append(res, found...), nil
+}
+
+func (r cachedProductRepository) Store(ctx context.Context, data *entity.Product) (res entity.Product, err error) {
+	defer r.write(ctx)
+	return r.repo.Store(ctx, data)
+}
+
+func (r cachedProductRepository) Update(ctx context.Context, data *entity.Product) (res entity.Product, err error) {
+	defer r.write(ctx)
+	return r.repo.Update(ctx, data)
+}
+
+func (r cachedProductRepository) Delete(ctx context.Context, data *entity.Product) (err error) {
+	defer r.write(ctx)
+	return r.repo.Delete(ctx, data)
+}
+
+func (r cachedProductRepository) AddStock(ctx context.Context, id uuid.UUID, quantity int) (
+	updated bool, err error,
+) {
+	defer r.write(ctx)
+	return r.repo.AddStock(ctx, id, quantity)
+}
+
+func (r cachedProductRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (
+	totalRow int64, err error,
+) {
+	key, ok := r.queryKey(ctx, "count", builder)
+	if !ok {
+		return r.repo.Count(ctx, builder)
+	}
+	if r.load(ctx, key, &totalRow) {
+		return totalRow, nil
+	}
+
+	totalRow, err = r.repo.Count(ctx, builder)
+	if err != nil {
+		return totalRow, err
+	}
+	r.store(ctx, key, totalRow)
+
+	return totalRow, nil
+}
+
+// generation returns the current generation of the keys, starting a new one when the cache lost it.
+func (r cachedProductRepository) generation(ctx context.Context) string {
+	if val, ok := r.cache.Get(ctx, productCacheName+":generation"); ok {
+		return string(val)
+	}
+
+	return r.invalidate(ctx)
+}
+
+// write invalidates the cache after a write, or once the transaction of the write ends.
+func (r cachedProductRepository) write(ctx context.Context) {
+	if r.written != nil {
+		*r.written = true
+		return
+	}
+	r.invalidate(ctx)
+}
+
+// invalidate starts a new generation, so that no entry cached so far is read again, and returns it. It is
+// called whatever the outcome of the write, a write that timed out may have been applied all the same.
+func (r cachedProductRepository) invalidate(ctx context.Context) string {
+	generation := uuid.NewString()
+	r.cache.Set(ctx, productCacheName+":generation", []byte(generation))
+
+	return generation
+}
+
+// queryKey returns the key of the result of operation for builder, which is the query it generates. It
+// returns false when the cache is bypassed or the query can't be generated, repo reporting the error.
+func (r cachedProductRepository) queryKey(ctx context.Context, operation string, builder *QueryBuilderCriteria) (
+	string, bool,
+) {
+	if r.inTx {
+		return "", false
+	}
+
+	sq, err := builder.GenerateSquirrelQuery(productCacheName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return "", false
+	}
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %q", query, args)))
+	key := fmt.Sprintf("%s:%s:%s:%s", productCacheName, r.generation(ctx), operation, hex.EncodeToString(sum[:]))
+
+	return key, true
+}
+
+func idKey(generation string, id uuid.UUID) string {
+	return fmt.Sprintf("%s:%s:id:%s", productCacheName, generation, id)
+}
+
+// load decodes the value of key into dest, counting the hit or miss.
+func (r cachedProductRepository) load(ctx context.Context, key string, dest interface{}) bool {
+	val, ok := r.cache.Get(ctx, key)
+	if ok {
+		if err := json.Unmarshal(val, dest); err != nil {
+			r.Log.WarnContext(ctx, "cache entry can't be decoded", "cache", productCacheName, "error", err)
+			ok = false
+		}
+	}
+
+	if ok {
+		metrics.CacheHits.WithLabelValues(productCacheName).Inc()
+	} else {
+		metrics.CacheMisses.WithLabelValues(productCacheName).Inc()
+	}
+
+	return ok
+}
+
+func (r cachedProductRepository) store(ctx context.Context, key string, value interface{}) {
+	val, err := json.Marshal(value)
+	if err != nil {
+		r.Log.WarnContext(ctx, "cache entry can't be encoded", "cache", productCacheName, "error", err)
+		return
+	}
+
+	r.cache.Set(ctx, key, val)
+}
@@ -0,0 +1,133 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/cache"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/metrics"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/util"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCachedProductRepositoryFind(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepo := mocks.NewMockProductRepository(ctrl)
+	repo := persistence.NewCachedProductRepository(productRepo, cache.NewLRU(100, time.Minute), slog.Default())
+
+	ctx := context.TODO()
+	product := entity.Product{
+		ID:            uuid.New(),
+		Name:          "Makanan",
+		Price:         util.MoneyFromInt(1000),
+		Category:      sql.NullString{String: "food", Valid: true},
+		DiscountValue: util.NullMoney{Money: util.MustMoney("12.50"), Valid: true},
+	}
+	builder := func() *persistence.QueryBuilderCriteria {
+		return &persistence.QueryBuilderCriteria{
+			Where: &persistence.Where{And: []squirrel.And{{squirrel.ILike{"name": "%makan%"}}}},
+		}
+	}
+	hits := testutil.ToFloat64(metrics.CacheHits.WithLabelValues("products"))
+	misses := testutil.ToFloat64(metrics.CacheMisses.WithLabelValues("products"))
+
+	productRepo.EXPECT().Find(gomock.Any(), builder()).Return([]entity.Product{product}, nil).Times(2)
+	productRepo.EXPECT().Count(gomock.Any(), builder()).Return(int64(1), nil).Times(1)
+	productRepo.EXPECT().Update(gomock.Any(), &product).Return(product, nil)
+
+	for i := 0; i < 2; i++ {
+		res, err := repo.Find(ctx, builder())
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, product.ID, res[0].ID)
+		assert.Equal(t, "1000.00", res[0].Price.String())
+		assert.Equal(t, product.Category, res[0].Category)
+		assert.Equal(t, "12.50", res[0].DiscountValue.Money.String())
+
+		totalRow, err := repo.Count(ctx, builder())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), totalRow)
+	}
+	assert.Equal(t, hits+2, testutil.ToFloat64(metrics.CacheHits.WithLabelValues("products")))
+	assert.Equal(t, misses+2, testutil.ToFloat64(metrics.CacheMisses.WithLabelValues("products")))
+
+	// the write empties the cache, the listing is read again
+	_, err := repo.Update(ctx, &product)
+	assert.NoError(t, err)
+	_, err = repo.Find(ctx, builder())
+	assert.NoError(t, err)
+}
+
+func TestCachedProductRepositoryFindByIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepo := mocks.NewMockProductRepository(ctrl)
+	repo := persistence.NewCachedProductRepository(productRepo, cache.NewLRU(100, time.Minute), slog.Default())
+
+	ctx := context.TODO()
+	a := entity.Product{ID: uuid.New(), Name: "A", Price: util.MoneyFromInt(1000)}
+	b := entity.Product{ID: uuid.New(), Name: "B", Price: util.MoneyFromInt(2000)}
+	c := entity.Product{ID: uuid.New(), Name: "C", Price: util.MoneyFromInt(3000)}
+
+	productRepo.EXPECT().FindByIDs(gomock.Any(), []uuid.UUID{a.ID, b.ID}).Return([]entity.Product{a, b}, nil)
+	res, err := repo.FindByIDs(ctx, []uuid.UUID{a.ID, b.ID})
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	// only the product not cached yet is asked for
+	productRepo.EXPECT().FindByIDs(gomock.Any(), []uuid.UUID{c.ID}).Return([]entity.Product{c}, nil)
+	res, err = repo.FindByIDs(ctx, []uuid.UUID{b.ID, c.ID, a.ID})
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+	names := make([]string, 0, len(res))
+	for _, val := range res {
+		names = append(names, val.Name)
+	}
+	assert.ElementsMatch(t, []string{"A", "B", "C"}, names)
+
+	res, err = repo.FindByIDs(ctx, []uuid.UUID{c.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, "3000.00", res[0].Price.String())
+}
+
+func TestCachedProductRepositoryWithTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productRepo := mocks.NewMockProductRepository(ctrl)
+	repo := persistence.NewCachedProductRepository(productRepo, cache.NewLRU(100, time.Minute), slog.Default())
+
+	ctx := context.TODO()
+	product := entity.Product{ID: uuid.New(), Name: "Makanan"}
+	builder := &persistence.QueryBuilderCriteria{
+		Where: &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": product.ID}}}},
+	}
+
+	productRepo.EXPECT().Get(gomock.Any(), builder).Return(product, nil).Times(3)
+	productRepo.EXPECT().WithTx(gomock.Any()).Return(productRepo)
+	productRepo.EXPECT().Store(gomock.Any(), &product).Return(product, nil)
+	productRepo.EXPECT().EndTx(gomock.Any())
+
+	_, err := repo.Get(ctx, builder)
+	assert.NoError(t, err)
+
+	// the transaction reads from the database, its write leaves the cache as it is until the transaction ends
+	productTx := repo.WithTx(nil)
+	_, err = productTx.Get(ctx, builder)
+	assert.NoError(t, err)
+	_, err = productTx.Store(ctx, &product)
+	assert.NoError(t, err)
+
+	_, err = repo.Get(ctx, builder)
+	assert.NoError(t, err)
+
+	productTx.EndTx(ctx)
+	_, err = repo.Get(ctx, builder)
+	assert.NoError(t, err)
+}
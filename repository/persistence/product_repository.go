@@ -4,11 +4,20 @@ import (
 	"context"
 	"fmt"
 	"interview-telkom-6/entity"
-	"log"
+	"interview-telkom-6/logger"
+	"interview-telkom-6/util"
+	"log/slog"
+	"time"
 
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrProductChanged is the ConflictError of an update made to a version of a product that isn't the current
+// one anymore.
+var ErrProductChanged = &util.ConflictError{Message: "the product was changed by another request"}
+
 type ProductRepository interface {
 	WithTx(conn *sqlx.Tx) ProductRepository
 	Get(ctx context.Context, builder *QueryBuilderCriteria) (
@@ -17,51 +26,57 @@ type ProductRepository interface {
 	Find(ctx context.Context, builder *QueryBuilderCriteria) (
 		res []entity.Product, err error,
 	)
+	FindByIDs(ctx context.Context, ids []uuid.UUID) (res []entity.Product, err error)
 	Store(ctx context.Context, data *entity.Product) (res entity.Product, err error)
 	Update(ctx context.Context, data *entity.Product) (res entity.Product, err error)
 	Delete(ctx context.Context, data *entity.Product) (err error)
+	AddStock(ctx context.Context, id uuid.UUID, quantity int) (updated bool, err error)
 	Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error)
+	// EndTx is called on a repository returned by WithTx once its transaction is committed or rolled back.
+	EndTx(ctx context.Context)
 }
 
 type productRepository struct {
 	Conn      Queryer
 	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
 }
 
-func NewProductRepository(conn *sqlx.DB) ProductRepository {
-	return &productRepository{Conn: conn, TableName: "products"}
+func NewProductRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) ProductRepository {
+	return &productRepository{Conn: conn, TableName: "products", Log: log, QueryTimeout: queryTimeout}
 }
 
 func (r productRepository) WithTx(conn *sqlx.Tx) ProductRepository {
 	if conn == nil {
-		log.Println("transaction database not found")
+		r.Log.Warn("transaction database not found", "table", r.TableName)
 		return &r
 	}
 
-	return &productRepository{Conn: conn, TableName: "products"}
+	return &productRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
 }
 
 func (r productRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
 	res entity.Product, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Get(&res, query, args...)
+	err = r.Conn.GetContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -71,24 +86,55 @@ func (r productRepository) Get(ctx context.Context, builder *QueryBuilderCriteri
 func (r productRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
 	res []entity.Product, err error,
 ) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+// FindByIDs returns the products of ids in a single query, in no particular order, leaving out the unknown
+// ids.
+func (r productRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) (res []entity.Product, err error) {
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	ctx, end := startQuery(ctx, r.TableName, "FindByIDs", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	builder := QueryBuilderCriteria{}
+	builder.Where = &Where{And: []squirrel.And{{squirrel.Eq{"id": ids}}}}
 	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	query, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	err = r.Conn.Select(&res, query, args...)
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
@@ -96,83 +142,124 @@ func (r productRepository) Find(ctx context.Context, builder *QueryBuilderCriter
 }
 
 func (r productRepository) Store(ctx context.Context, data *entity.Product) (res entity.Product, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	data.GenerateUUID()
 	query := fmt.Sprintf(
-		"INSERT INTO %s (id,  name, price, description, is_discount, "+
-			"discount_value, start_date_discount, end_date_discount) "+
-			"VALUES (:id, :name, :price, :description, :is_discount, :discount_value, "+
-			":start_date_discount, :end_date_discount)",
+		"INSERT INTO %s (id,  name, price, currency, description, weight, category, is_discount, discount_type, "+
+			"discount_value, start_date_discount, end_date_discount, stock) "+
+			"VALUES (:id, :name, :price, :currency, :description, :weight, :category, :is_discount, :discount_type, "+
+			":discount_value, :start_date_discount, :end_date_discount, :stock)",
 		r.TableName,
 	)
-	log.Println(query)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
 	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if isUniqueViolation(err) {
+		return res, &util.ConflictError{Message: "a product with this name was created by another request"}
+	}
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	data.Version = 1
 
 	return *data, err
 }
 
+// Update only applies to the version data was read at, ErrProductChanged telling the product changed since.
+// Renaming it to the name of another product is a ConflictError as well.
 func (r productRepository) Update(ctx context.Context, data *entity.Product) (res entity.Product, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Update", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf(
-		"UPDATE %s SET name=:name, price=:price, description=:description, "+
-			"is_discount=:is_discount, start_date_discount=:start_date_discount, end_date_discount=:end_date_discount WHERE id=:id",
+		"UPDATE %s SET name=:name, price=:price, currency=:currency, description=:description, weight=:weight, "+
+			"category=:category, is_discount=:is_discount, discount_type=:discount_type, discount_value=:discount_value, "+
+			"start_date_discount=:start_date_discount, end_date_discount=:end_date_discount, stock=:stock, "+
+			"version=version+1 "+
+			"WHERE id=:id AND version=:version",
 		r.TableName,
 	)
-	log.Println(query)
-	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.NamedExecContext(ctx, query, data)
+	if isUniqueViolation(err) {
+		return res, &util.ConflictError{Message: "a product with this name was created by another request"}
+	}
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	rows, _ = result.RowsAffected()
+	if rows == 0 {
+		return res, ErrProductChanged
+	}
+	data.Version++
 
 	return *data, nil
 }
 
 func (r productRepository) Delete(ctx context.Context, data *entity.Product) (err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "Delete", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.TableName)
-	log.Println(query)
-	_, err = r.Conn.Exec(query, data.ID)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.ID)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
+	rows, _ = result.RowsAffected()
 
 	return nil
 }
 
+func (r productRepository) EndTx(ctx context.Context) {}
+
+// AddStock adds quantity to the stock of the product id whatever its version, updated is false when there is
+// no such product anymore.
+func (r productRepository) AddStock(ctx context.Context, id uuid.UUID, quantity int) (updated bool, err error) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "AddStock", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("UPDATE %s SET stock = stock + $1, version = version + 1 WHERE id = $2", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, quantity, id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
 func (r productRepository) Count(ctx context.Context, builder *QueryBuilderCriteria) (totalRow int64, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Count", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
 	sq, err := builder.GenerateSquirrelQueryCountData(r.TableName, DATABASE_ENGINE_POSTGRESQL)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	queryFrom, args, err := sq.ToSql()
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS totalRow", queryFrom)
 
-	log.Println(query)
-	log.Println(args)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
 
-	rows, err := r.Conn.Queryx(query, args...)
+	err = r.Conn.GetContext(ctx, &totalRow, query, args...)
 	if err != nil {
-		log.Println(err)
 		return totalRow, err
 	}
 
-	for rows.Next() {
-		err = rows.Scan(&totalRow)
-		if err != nil {
-			log.Println(err)
-			return totalRow, err
-		}
-	}
-
 	return totalRow, nil
 }
@@ -0,0 +1,140 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/logger"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ReturnRepository interface {
+	WithTx(conn *sqlx.Tx) ReturnRepository
+	Get(ctx context.Context, builder *QueryBuilderCriteria) (
+		res entity.Return, err error,
+	)
+	Find(ctx context.Context, builder *QueryBuilderCriteria) (
+		res []entity.Return, err error,
+	)
+	Store(ctx context.Context, data *entity.Return) (res entity.Return, err error)
+	UpdateStatus(ctx context.Context, data *entity.Return, from string) (updated bool, err error)
+}
+
+type returnRepository struct {
+	Conn      Queryer
+	TableName string
+	Log       *slog.Logger
+	// QueryTimeout bounds every query, none when zero.
+	QueryTimeout time.Duration
+}
+
+func NewReturnRepository(conn *sqlx.DB, log *slog.Logger, queryTimeout time.Duration) ReturnRepository {
+	return &returnRepository{Conn: conn, TableName: "returns", Log: log, QueryTimeout: queryTimeout}
+}
+
+func (r returnRepository) WithTx(conn *sqlx.Tx) ReturnRepository {
+	if conn == nil {
+		r.Log.Warn("transaction database not found", "table", r.TableName)
+		return &r
+	}
+
+	return &returnRepository{Conn: conn, TableName: r.TableName, Log: r.Log, QueryTimeout: r.QueryTimeout}
+}
+
+func (r returnRepository) Get(ctx context.Context, builder *QueryBuilderCriteria) (
+	res entity.Return, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Get", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.GetContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r returnRepository) Find(ctx context.Context, builder *QueryBuilderCriteria) (
+	res []entity.Return, err error,
+) {
+	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+	defer func() { err = end(int64(len(res)), err) }()
+
+	sq, err := builder.GenerateSquirrelQuery(r.TableName, DATABASE_ENGINE_POSTGRESQL)
+	if err != nil {
+		return res, err
+	}
+
+	query, args, err := sq.ToSql()
+	if err != nil {
+		return res, err
+	}
+
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query, "args", logger.Redact(args))
+
+	err = r.Conn.SelectContext(ctx, &res, query, args...)
+	if err != nil {
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (r returnRepository) Store(ctx context.Context, data *entity.Return) (res entity.Return, err error) {
+	ctx, end := startQuery(ctx, r.TableName, "Store", r.QueryTimeout)
+	defer func() { err = end(1, err) }()
+
+	data.GenerateUUID()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, payment_id, status, reason, items, created_at, updated_at) "+
+			"VALUES (:id, :payment_id, :status, :reason, :items, :created_at, :updated_at)",
+		r.TableName,
+	)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	_, err = r.Conn.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return res, err
+	}
+
+	return *data, err
+}
+
+// UpdateStatus moves the return to data.Status only while it is still in status from, updated is false when
+// another request changed it first.
+func (r returnRepository) UpdateStatus(ctx context.Context, data *entity.Return, from string) (
+	updated bool, err error,
+) {
+	var rows int64
+	ctx, end := startQuery(ctx, r.TableName, "UpdateStatus", r.QueryTimeout)
+	defer func() { err = end(rows, err) }()
+
+	query := fmt.Sprintf("UPDATE %s SET status = $1, updated_at = $2 WHERE id = $3 AND status = $4", r.TableName)
+	r.Log.DebugContext(ctx, "query", "table", r.TableName, "sql", query)
+	result, err := r.Conn.ExecContext(ctx, query, data.Status, data.UpdatedAt, data.ID, from)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
@@ -16,17 +16,14 @@ const (
 	DATABASE_ENGINE_MYSQL               = "mysql"
 )
 
+// Queryer is the part of *sqlx.DB and *sqlx.Tx the repositories use. It only has the context variants, so
+// that a query is cancelled along with its request and bounded by the query timeout.
 type Queryer interface {
 	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
-	Exec(query string, args ...interface{}) (sql.Result, error)
-	Get(dest interface{}, query string, args ...interface{}) error
-	Select(dest interface{}, query string, args ...interface{}) error
-	Prepare(query string) (*sql.Stmt, error)
-	Query(query string, args ...interface{}) (*sql.Rows, error)
-	Rebind(query string) string
-	QueryRow(query string, args ...interface{}) *sql.Row
-	sqlx.Queryer
-	sqlx.Execer
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	sqlx.QueryerContext
+	sqlx.ExecerContext
 }
 
 type Where struct {
@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"interview-telkom-6/metrics"
+	"interview-telkom-6/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startQuery starts the span of the operation of a repository on table and bounds ctx by timeout, unless
+// zero. The returned function ends both with the number of rows returned or affected and the error,
+// sql.ErrNoRows counting as no row rather than a failure, records the query latency and returns the error
+// to return. The driver reports a cancelled query as a failed statement, the error then wraps the context
+// one so that callers can tell a timeout with errors.Is(err, context.DeadlineExceeded):
+//
+//	ctx, end := startQuery(ctx, r.TableName, "Find", r.QueryTimeout)
+//	defer func() { err = end(int64(len(res)), err) }()
+func startQuery(ctx context.Context, table string, operation string, timeout time.Duration) (
+	context.Context, func(rows int64, err error) error,
+) {
+	start := time.Now()
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	ctx, span := tracing.Start(
+		ctx, table+"."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			semconv.DBSystemPostgreSQL, semconv.DBCollectionName(table), semconv.DBOperationName(operation),
+		),
+	)
+
+	return ctx, func(rows int64, err error) error {
+		defer cancel()
+		metrics.ObserveQuery(table, operation, start)
+
+		if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+			err = fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+		spanErr := err
+		if errors.Is(err, sql.ErrNoRows) {
+			rows, spanErr = 0, nil
+		}
+		if spanErr == nil {
+			span.SetAttributes(attribute.Int64("db.rows", rows))
+		}
+		tracing.End(span, spanErr)
+
+		return err
+	}
+}
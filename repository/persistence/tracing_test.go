@@ -0,0 +1,33 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartQueryTimeout(t *testing.T) {
+	ctx, end := startQuery(context.TODO(), "carts", "Find", time.Millisecond)
+	<-ctx.Done()
+
+	// lib/pq reports the cancelled statement, not the context error
+	err := end(0, errors.New("pq: canceling statement due to user request"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "canceling statement")
+}
+
+func TestStartQueryKeepsErrors(t *testing.T) {
+	ctx, end := startQuery(context.TODO(), "carts", "Get", 0)
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, sql.ErrNoRows, end(0, sql.ErrNoRows))
+	assert.NoError(t, ctx.Err())
+
+	ctx, end = startQuery(context.TODO(), "carts", "Get", time.Minute)
+	assert.NoError(t, end(1, nil))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,26 @@
+package request
+
+type AddressAddRequest struct {
+	FullName   string `json:"full_name" binding:"required"`
+	Label      string `json:"label" binding:"required"`
+	Recipient  string `json:"recipient" binding:"required"`
+	Phone      string `json:"phone" binding:"required"`
+	Street     string `json:"street" binding:"required"`
+	City       string `json:"city" binding:"required"`
+	Province   string `json:"province" binding:"required"`
+	PostalCode string `json:"postal_code" binding:"required"`
+	Country    string `json:"country" binding:"required"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+type AddressUpdateRequest struct {
+	Label      string `json:"label" binding:"required"`
+	Recipient  string `json:"recipient" binding:"required"`
+	Phone      string `json:"phone" binding:"required"`
+	Street     string `json:"street" binding:"required"`
+	City       string `json:"city" binding:"required"`
+	Province   string `json:"province" binding:"required"`
+	PostalCode string `json:"postal_code" binding:"required"`
+	Country    string `json:"country" binding:"required"`
+	IsDefault  bool   `json:"is_default"`
+}
@@ -0,0 +1,10 @@
+package request
+
+// CartExportRequest exports the carts created between From and To, both inclusive dates formatted as
+// 2006-01-02 and optional, in Format, csv or jsonl. Amounts are converted to Currency when it is set.
+type CartExportRequest struct {
+	From     string
+	To       string
+	Currency string
+	Format   string
+}
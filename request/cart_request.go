@@ -18,4 +18,9 @@ type CartCriteria struct {
 	FullName    string `json:"full_name"`
 	Quantity    string `json:"quantity"`
 	ProductName string `json:"product_name"`
+	Currency    string `json:"currency"`
+	// AddressID and ShippingService pick the shipping included in the total, the default address of
+	// FullName is used when AddressID is empty.
+	AddressID       string `json:"address_id"`
+	ShippingService string `json:"shipping_service"`
 }
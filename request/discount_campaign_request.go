@@ -0,0 +1,25 @@
+package request
+
+import (
+	"interview-telkom-6/util"
+
+	"github.com/google/uuid"
+)
+
+type DiscountCampaignAddRequest struct {
+	Name         string      `json:"name" binding:"required"`
+	DiscountType string      `json:"discount_type" binding:"required,oneof=percentage fixed"`
+	Value        util.Money  `json:"value" binding:"required"`
+	Currency     string      `json:"currency"`
+	Priority     int         `json:"priority"`
+	IsStackable  bool        `json:"is_stackable"`
+	StartDate    string      `json:"start_date" binding:"required"`
+	EndDate      string      `json:"end_date" binding:"required"`
+	ProductIDs   []uuid.UUID `json:"product_ids"`
+	Categories   []string    `json:"categories"`
+}
+
+type DiscountCampaignCriteria struct {
+	Search string `json:"search"`
+	util.Pagination
+}
@@ -0,0 +1,9 @@
+package request
+
+import "interview-telkom-6/util"
+
+type ExchangeRateAddRequest struct {
+	BaseCurrency  string    `json:"base_currency" binding:"required,len=3"`
+	QuoteCurrency string    `json:"quote_currency" binding:"required,len=3"`
+	Rate          util.Rate `json:"rate" binding:"required"`
+}
@@ -0,0 +1,9 @@
+package request
+
+// PaymentAddRequest charges the cart of FullName, priced the same way as GET /api/carts.
+type PaymentAddRequest struct {
+	FullName        string `json:"full_name" binding:"required"`
+	Currency        string `json:"currency"`
+	AddressID       string `json:"address_id"`
+	ShippingService string `json:"shipping_service"`
+}
@@ -0,0 +1,10 @@
+package request
+
+const ExportFormatXLSX = "xlsx"
+
+// ProductExportRequest exports the products matching ProductCriteria in Format, csv, jsonl or xlsx.
+// Pagination is ignored, every matching product is exported.
+type ProductExportRequest struct {
+	ProductCriteria
+	Format string
+}
@@ -0,0 +1,14 @@
+package request
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// ProductImportRequest describes how an uploaded catalogue is imported. With DryRun nothing is written,
+// with Upsert rows matching an existing product name update that product instead of being rejected.
+type ProductImportRequest struct {
+	Format string
+	DryRun bool
+	Upsert bool
+}
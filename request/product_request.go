@@ -5,17 +5,24 @@ import (
 )
 
 type ProductAddRequest struct {
-	Name              string  `json:"name" binding:"required"`
-	Price             float64 `json:"price" binding:"required"`
-	Description       string  `json:"description" binding:"required"`
-	IsDiscount        bool    `json:"is_discount"`
-	StartDateDiscount string  `json:"start_date_discount"`
-	EndDateDiscount   string  `json:"end_date_discount"`
-	DiscountValue     float64 `json:"discount_value"`
+	Name              string     `json:"name" binding:"required"`
+	Price             util.Money `json:"price" binding:"required"`
+	Currency          string     `json:"currency"`
+	Description       string     `json:"description" binding:"required"`
+	Weight            int        `json:"weight"`
+	Category          string     `json:"category"`
+	IsDiscount        bool       `json:"is_discount"`
+	DiscountType      string     `json:"discount_type"`
+	StartDateDiscount string     `json:"start_date_discount"`
+	EndDateDiscount   string     `json:"end_date_discount"`
+	DiscountValue     util.Money `json:"discount_value"`
+	// Stock is left as it is on update when nil.
+	Stock *int `json:"stock"`
 }
 
 type ProductCriteria struct {
-	Search  string `json:"search"`
-	Popular string `json:"popular"`
+	Search   string `json:"search"`
+	Popular  string `json:"popular"`
+	Currency string `json:"currency"`
 	util.Pagination
 }
@@ -0,0 +1,11 @@
+package request
+
+import "interview-telkom-6/util"
+
+// RefundAddRequest refunds Amount of a payment, an empty Amount refunds whatever is left of it. With Items,
+// what was paid for them is refunded instead, Amount must then be empty.
+type RefundAddRequest struct {
+	Amount util.Money            `json:"amount"`
+	Reason string                `json:"reason" binding:"required"`
+	Items  []ItemQuantityRequest `json:"items"`
+}
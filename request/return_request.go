@@ -0,0 +1,15 @@
+package request
+
+import "github.com/google/uuid"
+
+// ItemQuantityRequest is a quantity of one of the products of a payment.
+type ItemQuantityRequest struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
+
+// ReturnAddRequest asks to send Items of a payment back.
+type ReturnAddRequest struct {
+	Reason string                `json:"reason" binding:"required"`
+	Items  []ItemQuantityRequest `json:"items" binding:"required"`
+}
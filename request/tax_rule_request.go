@@ -0,0 +1,10 @@
+package request
+
+import "interview-telkom-6/util"
+
+type TaxRuleAddRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Category    string     `json:"category"`
+	Rate        util.Money `json:"rate" binding:"required"`
+	IsInclusive bool       `json:"is_inclusive"`
+}
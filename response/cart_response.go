@@ -1,13 +1,24 @@
 package response
 
 import (
+	"interview-telkom-6/util"
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type CartResponse struct {
-	ID       uuid.UUID             `json:"id"`
-	Products []CartResponseProduct `json:"products"`
-	FullName string                `json:"full_name"`
+	ID            uuid.UUID               `json:"id"`
+	Products      []CartResponseProduct   `json:"products"`
+	FullName      string                  `json:"full_name"`
+	Currency      string                  `json:"currency"`
+	SubTotal      util.Money              `json:"sub_total"`
+	TotalDiscount util.Money              `json:"total_discount"`
+	Taxes         []TaxResponse           `json:"taxes"`
+	TotalTax      util.Money              `json:"total_tax"`
+	Weight        int                     `json:"weight"`
+	Shipping      *ShippingOptionResponse `json:"shipping"`
+	Total         util.Money              `json:"total"`
 }
 
 type CartResponseProduct struct {
@@ -16,10 +27,33 @@ type CartResponseProduct struct {
 }
 
 type CartProduct struct {
-	ID                uuid.UUID `json:"id"`
-	Name              string    `json:"name"`
-	Price             float64   `json:"price"`
-	IsDiscount        bool      `json:"is_discount"`
-	StartDateDiscount string    `json:"start_date_discount"`
-	EndDateDiscount   string    `json:"end_date_discount"`
+	ID                uuid.UUID  `json:"id"`
+	Name              string     `json:"name"`
+	Price             util.Money `json:"price"`
+	IsDiscount        bool       `json:"is_discount"`
+	StartDateDiscount string     `json:"start_date_discount"`
+	EndDateDiscount   string     `json:"end_date_discount"`
+}
+
+// CartExportResponse is one exported cart with its priced items, written as a JSON line.
+type CartExportResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	FullName      string           `json:"full_name"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Currency      string           `json:"currency"`
+	Items         []CartExportItem `json:"items"`
+	SubTotal      util.Money       `json:"sub_total"`
+	TotalDiscount util.Money       `json:"total_discount"`
+	TotalTax      util.Money       `json:"total_tax"`
+	Total         util.Money       `json:"total"`
+}
+
+type CartExportItem struct {
+	ProductID      uuid.UUID  `json:"product_id"`
+	Name           string     `json:"name"`
+	Category       string     `json:"category"`
+	Quantity       int        `json:"quantity"`
+	Price          util.Money `json:"price"`
+	DiscountAmount util.Money `json:"discount_amount"`
+	FinalPrice     util.Money `json:"final_price"`
 }
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DiscountCampaignResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	DiscountType string     `json:"discount_type"`
+	Value        util.Money `json:"value"`
+	Currency     string     `json:"currency"`
+	Priority     int        `json:"priority"`
+	IsStackable  bool       `json:"is_stackable"`
+	StartDate    time.Time  `json:"start_date"`
+	EndDate      time.Time  `json:"end_date"`
+}
+
+type AppliedDiscountResponse struct {
+	CampaignID   *uuid.UUID `json:"campaign_id"`
+	Name         string     `json:"name"`
+	DiscountType string     `json:"discount_type"`
+	Amount       util.Money `json:"amount"`
+}
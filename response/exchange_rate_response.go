@@ -0,0 +1,13 @@
+package response
+
+import (
+	"interview-telkom-6/util"
+	"time"
+)
+
+type ExchangeRateResponse struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          util.Rate `json:"rate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package response
+
+import (
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	CartID     uuid.UUID  `json:"cart_id"`
+	Provider   string     `json:"provider"`
+	Amount     util.Money `json:"amount"`
+	Currency   string     `json:"currency"`
+	Status     string     `json:"status"`
+	PaymentURL string     `json:"payment_url,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import "interview-telkom-6/util"
+
+// ProductImportResponse reports an import, Row is the line of the uploaded file a result is about.
+type ProductImportResponse struct {
+	DryRun  bool                    `json:"dry_run"`
+	Total   int                     `json:"total"`
+	Created int                     `json:"created"`
+	Updated int                     `json:"updated"`
+	Failed  int                     `json:"failed"`
+	Errors  []ProductImportRowError `json:"errors"`
+}
+
+type ProductImportRowError struct {
+	Row     int               `json:"row"`
+	Name    string            `json:"name"`
+	Message string            `json:"message"`
+	Fields  []util.FieldError `json:"fields,omitempty"`
+}
@@ -1,18 +1,29 @@
 package response
 
 import (
+	"interview-telkom-6/util"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ProductResponse struct {
-	ID                uuid.UUID  `json:"id"`
-	Name              string     `json:"name"`
-	Price             float64    `json:"price"`
-	Description       string     `json:"description"`
-	IsDiscount        bool       `json:"is_discount"`
-	StartDateDiscount *time.Time `json:"start_date_discount"`
-	EndDateDiscount   *time.Time `json:"end_date_discount"`
-	DiscountValue     float64    `json:"discount_value"`
+	ID                uuid.UUID                 `json:"id"`
+	Name              string                    `json:"name"`
+	Price             util.Money                `json:"price"`
+	Currency          string                    `json:"currency"`
+	Description       string                    `json:"description"`
+	Weight            int                       `json:"weight"`
+	Stock             int                       `json:"stock"`
+	Category          string                    `json:"category"`
+	IsDiscount        bool                      `json:"is_discount"`
+	DiscountType      string                    `json:"discount_type"`
+	StartDateDiscount *time.Time                `json:"start_date_discount"`
+	EndDateDiscount   *time.Time                `json:"end_date_discount"`
+	DiscountValue     util.Money                `json:"discount_value"`
+	DiscountAmount    util.Money                `json:"discount_amount"`
+	FinalPrice        util.Money                `json:"final_price"`
+	AppliedDiscounts  []AppliedDiscountResponse `json:"applied_discounts"`
+	// Version is the one to send back in If-Match to update the product.
+	Version int64 `json:"version"`
 }
@@ -0,0 +1,23 @@
+package response
+
+import (
+	"interview-telkom-6/util"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundResponse is a refund of a payment. Its Status stays pending when the answer of the provider couldn't
+// be recorded, the refund counts as given back all the same.
+type RefundResponse struct {
+	ID            uuid.UUID              `json:"id"`
+	PaymentID     uuid.UUID              `json:"payment_id"`
+	Amount        util.Money             `json:"amount"`
+	Reason        string                 `json:"reason"`
+	Status        string                 `json:"status"`
+	Items         []ItemQuantityResponse `json:"items"`
+	ReturnID      *uuid.UUID             `json:"return_id"`
+	PaymentStatus string                 `json:"payment_status"`
+	Refundable    util.Money             `json:"refundable"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
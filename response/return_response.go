@@ -0,0 +1,22 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ItemQuantityResponse struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+}
+
+type ReturnResponse struct {
+	ID        uuid.UUID              `json:"id"`
+	PaymentID uuid.UUID              `json:"payment_id"`
+	Status    string                 `json:"status"`
+	Reason    string                 `json:"reason"`
+	Items     []ItemQuantityResponse `json:"items"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import "interview-telkom-6/util"
+
+// ShippingOptionResponse is a shipping service quoted for a cart, Weight is in grams.
+type ShippingOptionResponse struct {
+	Service       string     `json:"service"`
+	Name          string     `json:"name"`
+	Zone          string     `json:"zone"`
+	Weight        int        `json:"weight"`
+	Cost          util.Money `json:"cost"`
+	Currency      string     `json:"currency"`
+	EstimatedDays int        `json:"estimated_days"`
+}
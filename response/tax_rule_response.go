@@ -0,0 +1,24 @@
+package response
+
+import (
+	"interview-telkom-6/util"
+
+	"github.com/google/uuid"
+)
+
+type TaxRuleResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Category    string     `json:"category"`
+	Rate        util.Money `json:"rate"`
+	IsInclusive bool       `json:"is_inclusive"`
+}
+
+// TaxResponse is the tax due under one rule, TaxableAmount never includes the tax itself.
+type TaxResponse struct {
+	Name          string     `json:"name"`
+	Rate          util.Money `json:"rate"`
+	IsInclusive   bool       `json:"is_inclusive"`
+	TaxableAmount util.Money `json:"taxable_amount"`
+	Amount        util.Money `json:"amount"`
+}
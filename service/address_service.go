@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"log/slog"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AddressService manages the address book of a customer. Carts have no user behind them, a customer is
+// identified by the full name its carts are stored under.
+type AddressService struct {
+	ctx         context.Context
+	addressRepo persistence.AddressRepository
+}
+
+func NewAddressService(ctx context.Context, addressRepo persistence.AddressRepository) *AddressService {
+	return &AddressService{ctx: ctx, addressRepo: addressRepo}
+}
+
+func (s *AddressService) Find(ctx context.Context, fullName string) (res []response.AddressResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AddressService.Find")
+	defer func() { tracing.End(span, err) }()
+
+	if fullName == "" {
+		return res, &util.BadRequestError{Message: "full name can't be null"}
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": fullName}}}}
+	builder.Order = map[string]string{"label": "ASC"}
+
+	results, err := s.addressRepo.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]response.AddressResponse, 0, len(results))
+	for _, val := range results {
+		res = append(res, buildAddressResponse(val))
+	}
+
+	return res, nil
+}
+
+// Store adds an address to the address book, the first address of a customer is always the default one.
+func (s *AddressService) Store(ctx context.Context, req *request.AddressAddRequest) (
+	res response.AddressResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "AddressService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	address := entity.Address{
+		FullName:   req.FullName,
+		Label:      req.Label,
+		Recipient:  req.Recipient,
+		Phone:      req.Phone,
+		Street:     req.Street,
+		City:       req.City,
+		Province:   req.Province,
+		PostalCode: req.PostalCode,
+		Country:    strings.ToUpper(req.Country),
+		IsDefault:  req.IsDefault,
+	}
+	if err := validateAddress(&address); err != nil {
+		return res, err
+	}
+
+	if !address.IsDefault {
+		builder := persistence.QueryBuilderCriteria{}
+		builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": address.FullName}}}}
+		total, err := s.addressRepo.Count(ctx, &builder)
+		if err != nil {
+			return res, err
+		}
+		address.IsDefault = total == 0
+	}
+
+	err = s.save(ctx, &address, func(repo persistence.AddressRepository) (err error) {
+		address, err = repo.Store(ctx, &address)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+
+	return buildAddressResponse(address), nil
+}
+
+func (s *AddressService) Update(ctx context.Context, id string, req *request.AddressUpdateRequest) (
+	res response.AddressResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "AddressService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	address, err := s.get(ctx, id)
+	if err != nil {
+		return res, err
+	}
+
+	address.Label = req.Label
+	address.Recipient = req.Recipient
+	address.Phone = req.Phone
+	address.Street = req.Street
+	address.City = req.City
+	address.Province = req.Province
+	address.PostalCode = req.PostalCode
+	address.Country = strings.ToUpper(req.Country)
+	address.IsDefault = req.IsDefault
+	if err := validateAddress(&address); err != nil {
+		return res, err
+	}
+
+	err = s.save(ctx, &address, func(repo persistence.AddressRepository) (err error) {
+		address, err = repo.Update(ctx, &address)
+		return err
+	})
+	if err != nil {
+		return res, err
+	}
+
+	return buildAddressResponse(address), nil
+}
+
+func (s *AddressService) Delete(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "AddressService.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	address, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.addressRepo.Delete(ctx, &address)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Resolve returns the address addressID of fullName, or its default address when addressID is empty.
+func (s *AddressService) Resolve(ctx context.Context, fullName, addressID string) (res entity.Address, err error) {
+	ctx, span := tracing.Start(ctx, "AddressService.Resolve")
+	defer func() { tracing.End(span, err) }()
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": fullName}}}}
+	if addressID == "" {
+		builder.Where.And = append(builder.Where.And, squirrel.And{squirrel.Eq{"is_default": true}})
+	} else {
+		id, err := uuid.Parse(addressID)
+		if err != nil {
+			return res, &util.BadRequestError{Message: "address not found"}
+		}
+		builder.Where.And = append(builder.Where.And, squirrel.And{squirrel.Eq{"id": id}})
+	}
+
+	res, err = s.addressRepo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, &util.BadRequestError{Message: "address not found"}
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+func (s *AddressService) get(ctx context.Context, id string) (res entity.Address, err error) {
+	addressID, err := uuid.Parse(id)
+	if err != nil {
+		return res, &util.BadRequestError{Message: "address not found"}
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": addressID}}}}
+	res, err = s.addressRepo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, &util.BadRequestError{Message: "address not found"}
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+// save runs write in a transaction, first taking the default flag off the other addresses when address
+// becomes the default one.
+func (s *AddressService) save(
+	ctx context.Context, address *entity.Address, write func(repo persistence.AddressRepository) error,
+) (err error) {
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	addressTx := s.addressRepo.WithTx(tx)
+	if address.IsDefault {
+		if err := addressTx.UnsetDefault(ctx, address.FullName); err != nil {
+			if err := tx.Rollback(); err != nil {
+				slog.WarnContext(ctx, "rollback failed", "error", err)
+			}
+			return err
+		}
+	}
+
+	if err := write(addressTx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateAddress(address *entity.Address) error {
+	fields := make([]util.FieldError, 0)
+	if len(address.Country) != 2 {
+		fields = append(fields, util.FieldError{Field: "country", Message: "must be a 2 letter country code"})
+	}
+
+	return util.NewValidationError(fields)
+}
+
+func buildAddressResponse(address entity.Address) response.AddressResponse {
+	return response.AddressResponse{
+		ID:         address.ID,
+		FullName:   address.FullName,
+		Label:      address.Label,
+		Recipient:  address.Recipient,
+		Phone:      address.Phone,
+		Street:     address.Street,
+		City:       address.City,
+		Province:   address.Province,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		IsDefault:  address.IsDefault,
+	}
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestFindAddress(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	addressMock := mocks.NewMockAddressRepository(mockCtrl)
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": "Rehan"}}}}
+	b.Order = map[string]string{"label": "ASC"}
+	addressMock.EXPECT().Find(gomock.Any(), &b).Return(
+		[]entity.Address{{ID: uuid.New(), FullName: "Rehan", Label: "Home", Country: "ID", IsDefault: true}}, nil,
+	)
+
+	addressSvc := service.NewAddressService(ctx, addressMock)
+	res, err := addressSvc.Find(ctx, "Rehan")
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, res[0].IsDefault)
+}
+
+func TestResolveAddress(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	addressMock := mocks.NewMockAddressRepository(mockCtrl)
+	address := entity.Address{ID: uuid.New(), FullName: "Rehan", Label: "Office", Country: "ID"}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"full_name": "Rehan"}}, {squirrel.Eq{"id": address.ID}}},
+	}
+	addressMock.EXPECT().Get(gomock.Any(), &b).Return(address, nil)
+
+	addressSvc := service.NewAddressService(ctx, addressMock)
+	res, err := addressSvc.Resolve(ctx, "Rehan", address.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, address, res)
+}
+
+func TestResolveAddressWithoutDefault(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	addressMock := mocks.NewMockAddressRepository(mockCtrl)
+	addressMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.Address{}, sql.ErrNoRows)
+
+	addressSvc := service.NewAddressService(ctx, addressMock)
+	_, err := addressSvc.Resolve(ctx, "Rehan", "")
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestStoreAddressInvalidCountry(t *testing.T) {
+	addressSvc := service.NewAddressService(context.TODO(), nil)
+
+	req := request.AddressAddRequest{FullName: "Rehan", Label: "Home", Country: "Indonesia"}
+	_, err := addressSvc.Store(context.TODO(), &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// cartExportBatchSize is the number of carts read per query while exporting, their lines and products being
+// loaded with one query each.
+const cartExportBatchSize = 100
+
+var cartExportColumns = []string{
+	"cart_id", "full_name", "created_at", "currency", "sub_total", "total_discount", "total_tax", "total",
+	"product_id", "product_name", "category", "quantity", "price", "discount_amount", "final_price",
+}
+
+// cartExporter writes exported carts in one format.
+type cartExporter interface {
+	Write(c response.CartExportResponse) error
+	Flush() error
+}
+
+// Export writes every cart created within the req date range to w, priced like Find but without shipping.
+// Carts are read by batches of cartExportBatchSize ordered by id and flushed batch by batch.
+func (s *cartService) Export(ctx context.Context, req *request.CartExportRequest, w io.Writer) (err error) {
+	ctx, span := tracing.Start(ctx, "CartService.Export")
+	defer func() { tracing.End(span, err) }()
+
+	where, err := cartExportFilter(req)
+	if err != nil {
+		return err
+	}
+
+	conv, err := s.currencySvc.Converter(req.Currency)
+	if err != nil {
+		return err
+	}
+
+	// rules are loaded once, every cart is taxed with the same ones
+	rules, err := s.taxSvc.Rules(ctx)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := newCartExporter(req.Format, w)
+	if err != nil {
+		return err
+	}
+
+	limit := uint64(cartExportBatchSize)
+	last := uuid.Nil
+	for {
+		builder := persistence.QueryBuilderCriteria{}
+		builder.Where = &persistence.Where{And: append([]squirrel.And{{squirrel.Gt{"id": last}}}, where...)}
+		builder.Order = map[string]string{"id": "ASC"}
+		builder.Limit = &limit
+
+		carts, err := s.cartRepo.Find(ctx, &builder)
+		if err != nil {
+			return err
+		}
+		if len(carts) == 0 {
+			break
+		}
+
+		results, err := s.priceCarts(ctx, carts, rules, conv)
+		if err != nil {
+			return err
+		}
+
+		for _, val := range results {
+			if err := exporter.Write(val); err != nil {
+				return err
+			}
+		}
+
+		if err := exporter.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		if len(carts) < cartExportBatchSize {
+			break
+		}
+		last = carts[len(carts)-1].ID
+	}
+
+	return nil
+}
+
+// priceCarts loads the lines and products of carts in one query each and prices every cart.
+func (s *cartService) priceCarts(
+	ctx context.Context, carts []entity.Cart, rules TaxRules, conv *CurrencyConverter,
+) (res []response.CartExportResponse, err error) {
+	cartIDs := make([]uuid.UUID, 0, len(carts))
+	for _, val := range carts {
+		cartIDs = append(cartIDs, val.ID)
+	}
+
+	cpBuilder := persistence.QueryBuilderCriteria{}
+	cpBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"cart_id": cartIDs}}}}
+	cartProducts, err := s.cartProductRepo.Find(ctx, &cpBuilder)
+	if err != nil {
+		return res, err
+	}
+
+	lines := make(map[uuid.UUID][]entity.CartProduct, len(carts))
+	for _, cp := range cartProducts {
+		lines[cp.CartID] = append(lines[cp.CartID], cp)
+	}
+
+	products, byID, err := s.findLineProducts(ctx, cartProducts)
+	if err != nil {
+		return res, err
+	}
+
+	discounts, err := s.discountSvc.BestDiscounts(ctx, products, time.Now())
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]response.CartExportResponse, 0, len(carts))
+	for _, cart := range carts {
+		cartProducts, cartLineProducts := matchLines(lines[cart.ID], byID)
+
+		data := response.CartResponse{Currency: conv.Currency}
+		taxableLines, err := priceCart(ctx, &data, cartProducts, cartLineProducts, discounts, conv)
+		if err != nil {
+			return res, err
+		}
+		taxes := rules.Breakdown(taxableLines, conv.Currency)
+
+		row := response.CartExportResponse{
+			ID:            cart.ID,
+			FullName:      cart.FullName,
+			CreatedAt:     cart.CreatedAt,
+			Currency:      conv.Currency,
+			Items:         make([]response.CartExportItem, 0, len(data.Products)),
+			SubTotal:      data.SubTotal,
+			TotalDiscount: data.TotalDiscount,
+			TotalTax:      taxes.Total,
+			Total:         data.SubTotal.Sub(data.TotalDiscount).Add(taxes.Exclusive),
+		}
+		for _, p := range data.Products {
+			row.Items = append(
+				row.Items, response.CartExportItem{
+					ProductID:      p.Product.ID,
+					Name:           p.Product.Name,
+					Category:       p.Product.Category,
+					Quantity:       p.Quantity,
+					Price:          p.Product.Price,
+					DiscountAmount: p.Product.DiscountAmount,
+					FinalPrice:     p.Product.FinalPrice,
+				},
+			)
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// cartExportFilter validates the format and date range of req and returns the matching conditions.
+func cartExportFilter(req *request.CartExportRequest) (res []squirrel.And, err error) {
+	fields := make([]util.FieldError, 0)
+	if req.Format != request.ImportFormatCSV && req.Format != request.ImportFormatJSONL {
+		fields = append(fields, util.FieldError{Field: "format", Message: "must be csv or jsonl"})
+	}
+
+	var from, to time.Time
+	if req.From != "" {
+		if from, err = time.ParseInLocation(dateLayout, req.From, time.Local); err != nil {
+			fields = append(fields, util.FieldError{Field: "from", Message: "must be formatted as " + dateLayout})
+		} else {
+			res = append(res, squirrel.And{squirrel.GtOrEq{"created_at": from}})
+		}
+	}
+	if req.To != "" {
+		if to, err = time.ParseInLocation(dateLayout, req.To, time.Local); err != nil {
+			fields = append(fields, util.FieldError{Field: "to", Message: "must be formatted as " + dateLayout})
+		} else {
+			res = append(res, squirrel.And{squirrel.Lt{"created_at": to.AddDate(0, 0, 1)}})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		fields = append(fields, util.FieldError{Field: "to", Message: "must not be before from"})
+	}
+
+	if len(fields) > 0 {
+		return nil, util.NewValidationError(fields)
+	}
+
+	return res, nil
+}
+
+func newCartExporter(format string, w io.Writer) (cartExporter, error) {
+	if format == request.ImportFormatJSONL {
+		return &jsonlCartExporter{enc: json.NewEncoder(w)}, nil
+	}
+
+	cw := csv.NewWriter(w)
+	return &csvCartExporter{w: cw}, cw.Write(cartExportColumns)
+}
+
+// csvCartExporter writes one row per cart item, the cart columns being repeated on each of them. An empty
+// cart is written as a single row with empty item columns.
+type csvCartExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvCartExporter) Write(c response.CartExportResponse) error {
+	cart := []string{
+		c.ID.String(), c.FullName, c.CreatedAt.Format(time.RFC3339), c.Currency, c.SubTotal.String(),
+		c.TotalDiscount.String(), c.TotalTax.String(), c.Total.String(),
+	}
+	if len(c.Items) == 0 {
+		return e.w.Write(append(cart, "", "", "", "", "", "", ""))
+	}
+
+	for _, item := range c.Items {
+		record := append(
+			cart[:len(cart):len(cart)], item.ProductID.String(), item.Name, item.Category,
+			strconv.Itoa(item.Quantity), item.Price.String(), item.DiscountAmount.String(),
+			item.FinalPrice.String(),
+		)
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *csvCartExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlCartExporter struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlCartExporter) Write(c response.CartExportResponse) error {
+	return e.enc.Encode(c)
+}
+
+func (e *jsonlCartExporter) Flush() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newExportCartService(t *testing.T, carts []entity.Cart, lines []entity.CartProduct, products []entity.Product) (
+	service.CartService, context.Context,
+) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	cartRepo := mocks.NewMockCartRepository(ctrl)
+	cartProductRepo := mocks.NewMockCartProductRepository(ctrl)
+	productRepo := mocks.NewMockProductRepository(ctrl)
+	campaignRepo := mocks.NewMockDiscountCampaignRepository(ctrl)
+	campaignTargetRepo := mocks.NewMockDiscountCampaignTargetRepository(ctrl)
+	taxRuleRepo := mocks.NewMockTaxRuleRepository(ctrl)
+
+	// a single batch: one query for the carts, one for their lines and one for the products
+	cartRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(carts, nil)
+	cartProductRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(lines, nil)
+	productRepo.EXPECT().FindByIDs(gomock.Any(), gomock.Any()).Return(products, nil)
+	campaignTargetRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+	ppn := entity.TaxRule{ID: uuid.New(), Name: "PPN", Rate: util.MoneyFromInt(11)}
+	taxRuleRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.TaxRule{ppn}, nil).Times(1)
+
+	return service.NewCartService(
+		ctx, cartRepo, cartProductRepo, productRepo, service.NewDiscountService(ctx, campaignRepo, campaignTargetRepo, nil),
+		service.NewCurrencyService(mocks.NewMockExchangeRateRepository(ctrl)), service.NewTaxService(taxRuleRepo),
+		nil, service.NewDefaultShippingCalculator(),
+	), ctx
+}
+
+func TestExportCartsJSONL(t *testing.T) {
+	product := entity.Product{ID: uuid.New(), Name: "Kopi", Price: util.MoneyFromInt(1000), Currency: "IDR"}
+	carts := []entity.Cart{
+		{ID: uuid.New(), FullName: "Rehan", CreatedAt: time.Now()},
+		{ID: uuid.New(), FullName: "Budi", CreatedAt: time.Now()},
+	}
+	lines := []entity.CartProduct{{CartID: carts[0].ID, ProductID: product.ID, Quantity: 2}}
+	cartSvc, ctx := newExportCartService(t, carts, lines, []entity.Product{product})
+
+	var buf bytes.Buffer
+	err := cartSvc.Export(ctx, &request.CartExportRequest{Format: request.ImportFormatJSONL}, &buf)
+	assert.NoError(t, err)
+
+	rows := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, rows, 2)
+	assert.Contains(t, rows[0], `"full_name":"Rehan"`)
+	assert.Contains(t, rows[0], `"quantity":2`)
+	assert.Contains(t, rows[0], `"total_tax":"220.00"`)
+	assert.Contains(t, rows[0], `"total":"2220.00"`)
+	assert.Contains(t, rows[1], `"items":[]`)
+}
+
+func TestExportCartsCSV(t *testing.T) {
+	products := []entity.Product{
+		{ID: uuid.New(), Name: "Kopi", Price: util.MoneyFromInt(1000), Currency: "IDR"},
+		{ID: uuid.New(), Name: "Teh", Price: util.MoneyFromInt(500), Currency: "IDR"},
+	}
+	carts := []entity.Cart{{ID: uuid.New(), FullName: "Rehan", CreatedAt: time.Now()}}
+	lines := []entity.CartProduct{
+		{CartID: carts[0].ID, ProductID: products[0].ID, Quantity: 1},
+		{CartID: carts[0].ID, ProductID: products[1].ID, Quantity: 3},
+	}
+	cartSvc, ctx := newExportCartService(t, carts, lines, products)
+
+	var buf bytes.Buffer
+	err := cartSvc.Export(ctx, &request.CartExportRequest{Format: request.ImportFormatCSV}, &buf)
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "cart_id", records[0][0])
+	assert.Equal(t, records[1][0], records[2][0])
+	assert.Equal(t, "2500.00", records[1][4])
+	assert.Equal(t, "Teh", records[2][9])
+	assert.Equal(t, "3", records[2][11])
+}
+
+func TestExportCartsInvalidRange(t *testing.T) {
+	cartSvc := service.NewCartService(context.TODO(), nil, nil, nil, nil, nil, nil, nil, nil)
+
+	var buf bytes.Buffer
+	err := cartSvc.Export(
+		context.TODO(),
+		&request.CartExportRequest{Format: request.ImportFormatCSV, From: "2024-02-01", To: "2024-01-01"}, &buf,
+	)
+	assert.IsType(t, &util.BadRequestError{}, err)
+	assert.Zero(t, buf.Len())
+}
@@ -3,12 +3,17 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"interview-telkom-6/entity"
+	"interview-telkom-6/metrics"
 	"interview-telkom-6/repository/persistence"
 	"interview-telkom-6/request"
 	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
 	"interview-telkom-6/util"
-	"log"
+	"io"
+	"log/slog"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
@@ -20,13 +25,21 @@ type cartService struct {
 	cartRepo        persistence.CartRepository
 	cartProductRepo persistence.CartProductRepository
 	productRepo     persistence.ProductRepository
+	discountSvc     *DiscountService
+	currencySvc     *CurrencyService
+	taxSvc          *TaxService
+	addressSvc      *AddressService
+	shippingCalc    ShippingCalculator
 }
 
 type CartService interface {
 	DeleteProduct(ctx context.Context, productID string) error
+	Export(ctx context.Context, req *request.CartExportRequest, w io.Writer) error
 	Find(ctx context.Context, req *request.CartCriteria) (
 		res *response.CartResponse, err error,
 	)
+	Purge(ctx context.Context, olderThan time.Duration) (deleted int64, err error)
+	ShippingOptions(ctx context.Context, req *request.CartCriteria) (res []response.ShippingOptionResponse, err error)
 	Store(ctx context.Context, req *request.CartAddRequest) (res *response.CartResponse, err error)
 }
 
@@ -34,13 +47,23 @@ func NewCartService(
 	ctx context.Context, cartRepo persistence.CartRepository,
 	cartProductRepo persistence.CartProductRepository,
 	productRepo persistence.ProductRepository,
+	discountSvc *DiscountService,
+	currencySvc *CurrencyService,
+	taxSvc *TaxService,
+	addressSvc *AddressService,
+	shippingCalc ShippingCalculator,
 ) CartService {
 	return &cartService{
 		ctx: ctx, cartRepo: cartRepo, cartProductRepo: cartProductRepo, productRepo: productRepo,
+		discountSvc: discountSvc, currencySvc: currencySvc, taxSvc: taxSvc, addressSvc: addressSvc,
+		shippingCalc: shippingCalc,
 	}
 }
 
-func (s *cartService) DeleteProduct(ctx context.Context, productID string) error {
+func (s *cartService) DeleteProduct(ctx context.Context, productID string) (err error) {
+	ctx, span := tracing.Start(ctx, "CartService.DeleteProduct")
+	defer func() { tracing.End(span, err) }()
+
 	if productID == "" {
 		return &util.BadRequestError{Message: "product id not found"}
 	}
@@ -49,7 +72,6 @@ func (s *cartService) DeleteProduct(ctx context.Context, productID string) error
 	cpBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"product_id": productID}}}}
 	cp, err := s.cartProductRepo.Get(ctx, &cpBuilder)
 	if err != nil {
-		log.Println(err)
 		if err == sql.ErrNoRows {
 			return &util.BadRequestError{Message: "product not found"}
 		}
@@ -59,7 +81,6 @@ func (s *cartService) DeleteProduct(ctx context.Context, productID string) error
 
 	err = s.cartProductRepo.Delete(ctx, &cp)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
@@ -69,15 +90,23 @@ func (s *cartService) DeleteProduct(ctx context.Context, productID string) error
 func (s *cartService) Find(ctx context.Context, req *request.CartCriteria) (
 	res *response.CartResponse, err error,
 ) {
+	ctx, span := tracing.Start(ctx, "CartService.Find")
+	defer func() { tracing.End(span, err) }()
+
 	builder := persistence.QueryBuilderCriteria{}
 	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": req.FullName}}}}
 	if req.FullName == "" {
 		return res, &util.BadRequestError{Message: "full name can't be null"}
 	}
 
+	// a cart is always totalled in a single currency
+	conv, err := s.currencySvc.Converter(req.Currency)
+	if err != nil {
+		return res, err
+	}
+
 	result, err := s.cartRepo.Get(ctx, &builder)
 	if err != nil {
-		log.Println(err)
 		if err == sql.ErrNoRows {
 			return res, nil
 		}
@@ -87,6 +116,7 @@ func (s *cartService) Find(ctx context.Context, req *request.CartCriteria) (
 	data := response.CartResponse{
 		ID:       result.ID,
 		FullName: result.FullName,
+		Currency: conv.Currency,
 		Products: make([]response.CartResponseProduct, 0),
 	}
 
@@ -110,159 +140,317 @@ func (s *cartService) Find(ctx context.Context, req *request.CartCriteria) (
 	}
 	cartProducts, err := s.cartProductRepo.Find(ctx, &cpBuilder)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	for _, cp := range cartProducts {
-		p := response.CartResponseProduct{
-			Quantity: cp.Quantity,
-		}
+	products, byID, err := s.findLineProducts(ctx, cartProducts)
+	if err != nil {
+		return res, err
+	}
+	cartProducts, lineProducts := matchLines(cartProducts, byID)
 
-		pBuilder := persistence.QueryBuilderCriteria{}
-		pBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": cp.ProductID}}}}
-		product, err := s.productRepo.Get(ctx, &pBuilder)
+	discounts, err := s.discountSvc.BestDiscounts(ctx, products, time.Now())
+	if err != nil {
+		return res, err
+	}
+
+	taxableLines, err := priceCart(ctx, &data, cartProducts, lineProducts, discounts, conv)
+	if err != nil {
+		return res, err
+	}
+
+	taxes, err := s.taxSvc.Breakdown(ctx, taxableLines, conv.Currency)
+	if err != nil {
+		return res, err
+	}
+
+	// inclusive taxes are already part of the prices, only exclusive ones are added to the total
+	data.Taxes = taxes.Taxes
+	data.TotalTax = taxes.Total
+	data.Total = data.SubTotal.Sub(data.TotalDiscount).Add(taxes.Exclusive)
+
+	if req.ShippingService != "" {
+		options, err := s.quoteShipping(ctx, req, data.Weight, conv)
 		if err != nil {
-			log.Println(err)
 			return res, err
 		}
 
-		p.Product.ID = product.ID
-		p.Product.Name = product.Name
-		p.Product.Price = product.Price
-		p.Product.Description = product.Description
-		p.Product.IsDiscount = product.IsDiscount
-		p.Product.StartDateDiscount = &product.StartDateDiscount.Time
-		p.Product.EndDateDiscount = &product.EndDateDiscount.Time
+		for i := range options {
+			if options[i].Service == req.ShippingService {
+				data.Shipping = &options[i]
+			}
+		}
+		if data.Shipping == nil {
+			return res, util.NewValidationError(
+				[]util.FieldError{{Field: "shipping_service", Message: "shipping service isn't available"}},
+			)
+		}
 
-		data.Products = append(data.Products, p)
+		data.Total = data.Total.Add(data.Shipping.Cost)
 	}
 
 	return &data, nil
 }
 
-func (s *cartService) Store(ctx context.Context, req *request.CartAddRequest) (res *response.CartResponse, err error) {
-	tx, err := s.ctx.Value("db").(*sqlx.DB).Beginx()
+// Purge deletes the carts created more than olderThan ago, carts with a payment are kept for their invoice.
+func (s *cartService) Purge(ctx context.Context, olderThan time.Duration) (deleted int64, err error) {
+	ctx, span := tracing.Start(ctx, "CartService.Purge")
+	defer func() { tracing.End(span, err) }()
+
+	if olderThan <= 0 {
+		return 0, util.NewValidationError([]util.FieldError{{Field: "older_than", Message: "must be positive"}})
+	}
+
+	deleted, err = s.cartRepo.DeleteCreatedBefore(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// findLineProducts loads the products of lines in a single query, whatever the number of lines, and returns
+// them along with an index by id.
+func (s *cartService) findLineProducts(ctx context.Context, lines []entity.CartProduct) (
+	res []entity.Product, byID map[uuid.UUID]entity.Product, err error,
+) {
+	ids := make([]uuid.UUID, 0, len(lines))
+	seen := make(map[uuid.UUID]bool, len(lines))
+	for _, cp := range lines {
+		if !seen[cp.ProductID] {
+			seen[cp.ProductID] = true
+			ids = append(ids, cp.ProductID)
+		}
+	}
+
+	res, err = s.productRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID = make(map[uuid.UUID]entity.Product, len(res))
+	for _, val := range res {
+		byID[val.ID] = val
+	}
+
+	return res, byID, nil
+}
+
+// matchLines pairs lines with their product, products[i] being the product of res[i]. Lines whose product
+// was deleted are left out, as there is nothing left to price them with.
+func matchLines(lines []entity.CartProduct, byID map[uuid.UUID]entity.Product) (
+	res []entity.CartProduct, products []entity.Product,
+) {
+	res = make([]entity.CartProduct, 0, len(lines))
+	products = make([]entity.Product, 0, len(lines))
+	for _, cp := range lines {
+		if p, ok := byID[cp.ProductID]; ok {
+			res = append(res, cp)
+			products = append(products, p)
+		}
+	}
+
+	return res, products
+}
+
+// priceCart adds the cart lines to data, products[i] being the product of cartProducts[i], and sums their
+// prices and discounts in the converter currency. It returns the lines to tax, taxes are left to the caller.
+func priceCart(
+	ctx context.Context, data *response.CartResponse, cartProducts []entity.CartProduct, products []entity.Product,
+	discounts map[uuid.UUID]AppliedDiscount, conv *CurrencyConverter,
+) (res []TaxableLine, err error) {
+	res = make([]TaxableLine, 0, len(cartProducts))
+	for i, cp := range cartProducts {
+		p := response.CartResponseProduct{
+			Product:  buildProductResponse(products[i], discounts[products[i].ID]),
+			Quantity: cp.Quantity,
+		}
+		if err := convertProductResponse(ctx, &p.Product, conv); err != nil {
+			return res, err
+		}
+
+		quantity := int64(cp.Quantity)
+		data.SubTotal = data.SubTotal.Add(p.Product.Price.MulInt(quantity))
+		data.TotalDiscount = data.TotalDiscount.Add(p.Product.DiscountAmount.MulInt(quantity))
+		data.Weight += p.Product.Weight * cp.Quantity
+		data.Products = append(data.Products, p)
+		res = append(res, TaxableLine{Category: p.Product.Category, Amount: p.Product.FinalPrice.MulInt(quantity)})
+	}
+
+	return res, nil
+}
+
+// ShippingOptions quotes every shipping service able to deliver the cart of req.FullName to the address
+// req.AddressID, costs are converted to the cart currency.
+func (s *cartService) ShippingOptions(ctx context.Context, req *request.CartCriteria) (
+	res []response.ShippingOptionResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "CartService.ShippingOptions")
+	defer func() { tracing.End(span, err) }()
+
+	cartReq := *req
+	cartReq.ShippingService = ""
+	cart, err := s.Find(ctx, &cartReq)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
+	if cart == nil {
+		return res, &util.BadRequestError{Message: "cart not found"}
+	}
 
-	builder := persistence.QueryBuilderCriteria{}
-	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": req.FullName}}}}
-	checkCart, err := s.cartRepo.Get(ctx, &builder)
-	if err != sql.ErrNoRows && err != nil {
-		log.Println(err)
+	conv, err := s.currencySvc.Converter(cart.Currency)
+	if err != nil {
 		return res, err
 	}
 
+	return s.quoteShipping(ctx, req, cart.Weight, conv)
+}
+
+func (s *cartService) quoteShipping(
+	ctx context.Context, req *request.CartCriteria, weight int, conv *CurrencyConverter,
+) (res []response.ShippingOptionResponse, err error) {
+	address, err := s.addressSvc.Resolve(ctx, req.FullName, req.AddressID)
+	if err != nil {
+		return res, err
+	}
+
+	options, err := s.shippingCalc.Quote(ctx, Parcel{Destination: address, Weight: weight})
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]response.ShippingOptionResponse, 0, len(options))
+	for _, option := range options {
+		cost, err := conv.Convert(ctx, option.Cost, option.Currency)
+		if err != nil {
+			return res, err
+		}
+
+		res = append(
+			res, response.ShippingOptionResponse{
+				Service:       option.Service,
+				Name:          option.Name,
+				Zone:          option.Zone,
+				Weight:        weight,
+				Cost:          cost,
+				Currency:      conv.Currency,
+				EstimatedDays: option.EstimatedDays,
+			},
+		)
+	}
+
+	return res, nil
+}
+
+// maxCartAttempts bounds how many times adding a product is tried again after another request changed the
+// same cart line in between.
+const maxCartAttempts = 3
+
+func (s *cartService) Store(ctx context.Context, req *request.CartAddRequest) (res *response.CartResponse, err error) {
+	ctx, span := tracing.Start(ctx, "CartService.Store")
+	defer func() { tracing.End(span, err) }()
+
 	pBuilder := persistence.QueryBuilderCriteria{}
 	pBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": req.Product.ProductID}}}}
 	_, err = s.productRepo.Get(ctx, &pBuilder)
 	if err != nil {
-		log.Println(err)
 		if err == sql.ErrNoRows {
 			return res, &util.BadRequestError{Message: "product not found"}
 		}
 		return res, err
 	}
 
-	cartTx := s.cartRepo.WithTx(tx)
-	cartProductTx := s.cartProductRepo.WithTx(tx)
-
-	// if cart already exist, just insert products to cart
-	if checkCart.ID != uuid.Nil {
-		cPBuilder := persistence.QueryBuilderCriteria{}
-		cPBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"product_id": req.Product.ProductID}}}}
-		cp, err := s.cartProductRepo.Get(ctx, &cPBuilder)
-		if err != sql.ErrNoRows && err != nil {
-			log.Println(err)
-			if err := tx.Rollback(); err != nil {
-				log.Println(err)
-				return res, err
-			}
+	var created bool
+	for attempt := 1; ; attempt++ {
+		tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+		if err != nil {
 			return res, err
 		}
 
-		// if product isn't exist in cart, just insert product id to cart
-		if err == sql.ErrNoRows {
-			err = s.insertCartProduct(ctx, checkCart.ID, &req.Product, cartProductTx)
-			if err != nil {
-				log.Println(err)
-				if err := tx.Rollback(); err != nil {
-					log.Println(err)
-					return res, err
-				}
-				return res, err
-			}
-			if err := tx.Commit(); err != nil {
-				log.Println(err)
-				return res, err
-			}
-			return res, nil
-		}
-
-		// if product is exist in cart, just update quantity
-		cp.Quantity += req.Product.Quantity
-		_, err = s.cartProductRepo.Update(ctx, &cp)
+		created, err = s.addProduct(ctx, tx, req)
 		if err != nil {
-			log.Println(err)
 			if err := tx.Rollback(); err != nil {
-				log.Println(err)
-				return res, err
+				slog.WarnContext(ctx, "rollback failed", "error", err)
 			}
-			return res, err
-		}
-		if err := tx.Commit(); err != nil {
-			log.Println(err)
-			return res, err
+		} else {
+			err = tx.Commit()
 		}
 
-		reqFind := request.CartCriteria{FullName: req.FullName}
-		res, err = s.Find(ctx, &reqFind)
+		var conflict *util.ConflictError
+		if errors.As(err, &conflict) && attempt < maxCartAttempts {
+			slog.InfoContext(ctx, "cart changed concurrently, adding the product again", "attempt", attempt)
+			continue
+		}
 		if err != nil {
-			log.Println(err)
 			return res, err
 		}
+		break
+	}
 
-		return res, nil
+	if created {
+		metrics.CartsCreated.Inc()
 	}
+	metrics.CartItemsAdded.Add(float64(req.Product.Quantity))
 
-	// if cart isn't exist, create cart and insert products to cart
-	cartEntity := entity.Cart{FullName: req.FullName}
-	cart, err := cartTx.Store(ctx, &cartEntity)
+	reqFind := request.CartCriteria{FullName: req.FullName}
+	res, err = s.Find(ctx, &reqFind)
 	if err != nil {
-		log.Println(err)
-		if err := tx.Rollback(); err != nil {
-			log.Println(err)
-			return res, err
-		}
 		return res, err
 	}
 
-	err = s.insertCartProduct(ctx, cart.ID, &req.Product, cartProductTx)
-	if err != nil {
-		log.Println(err)
-		if err := tx.Rollback(); err != nil {
-			log.Println(err)
-			return res, err
+	return res, nil
+}
+
+// addProduct adds the product of req to the cart of the customer within tx, creating the cart when the
+// customer has none. created tells whether it did, which fails with a util.ConflictError when another request
+// created it in between. The quantity of a product already in the cart is increased, which fails with a
+// util.ConflictError when another request changed the line since it was read.
+func (s *cartService) addProduct(ctx context.Context, tx *sqlx.Tx, req *request.CartAddRequest) (
+	created bool, err error,
+) {
+	cartTx := s.cartRepo.WithTx(tx)
+	cartProductTx := s.cartProductRepo.WithTx(tx)
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": req.FullName}}}}
+	cart, err := cartTx.Get(ctx, &builder)
+	if err != sql.ErrNoRows && err != nil {
+		return false, err
+	}
+
+	// if cart isn't exist, create cart and insert products to cart
+	if err == sql.ErrNoRows {
+		cartEntity := entity.Cart{FullName: req.FullName, CreatedAt: time.Now()}
+		cart, err = cartTx.Store(ctx, &cartEntity)
+		if err != nil {
+			return false, err
 		}
-		return res, err
+
+		return true, s.insertCartProduct(ctx, cart.ID, &req.Product, cartProductTx)
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Println(err)
-		return res, err
+	cPBuilder := persistence.QueryBuilderCriteria{}
+	cPBuilder.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"cart_id": cart.ID}, squirrel.Eq{"product_id": req.Product.ProductID}}},
+	}
+	cp, err := cartProductTx.Get(ctx, &cPBuilder)
+	if err != sql.ErrNoRows && err != nil {
+		return false, err
 	}
 
-	reqFind := request.CartCriteria{FullName: req.FullName}
-	res, err = s.Find(ctx, &reqFind)
+	// if product isn't exist in cart, just insert product id to cart
+	if err == sql.ErrNoRows {
+		return false, s.insertCartProduct(ctx, cart.ID, &req.Product, cartProductTx)
+	}
+
+	// if product is exist in cart, just update quantity
+	cp.Quantity += req.Product.Quantity
+	_, err = cartProductTx.Update(ctx, &cp)
 	if err != nil {
-		log.Println(err)
-		return res, err
+		return false, err
 	}
 
-	return res, nil
+	return false, nil
 }
 
 func (s *cartService) insertCartProduct(
@@ -277,7 +465,6 @@ func (s *cartService) insertCartProduct(
 
 	_, err = cartRepoProduct.Store(ctx, &cp)
 	if err != nil {
-		log.Println(err)
 		return err
 	}
 
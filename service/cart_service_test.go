@@ -3,6 +3,7 @@ package service_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/Masterminds/squirrel"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -12,7 +13,9 @@ import (
 	"interview-telkom-6/repository/persistence/mocks"
 	"interview-telkom-6/request"
 	"interview-telkom-6/service"
+	"interview-telkom-6/util"
 	"testing"
+	"time"
 )
 
 func TestFindCart(t *testing.T) {
@@ -20,6 +23,11 @@ func TestFindCart(t *testing.T) {
 	cartRepo := mocks.NewMockCartRepository(ctrl)
 	cartProductRepo := mocks.NewMockCartProductRepository(ctrl)
 	productRepo := mocks.NewMockProductRepository(ctrl)
+	campaignRepo := mocks.NewMockDiscountCampaignRepository(ctrl)
+	campaignTargetRepo := mocks.NewMockDiscountCampaignTargetRepository(ctrl)
+	exchangeRateRepo := mocks.NewMockExchangeRateRepository(ctrl)
+	taxRuleRepo := mocks.NewMockTaxRuleRepository(ctrl)
+	addressRepo := mocks.NewMockAddressRepository(ctrl)
 
 	res := entity.Cart{
 		ID:       uuid.New(),
@@ -30,29 +38,29 @@ func TestFindCart(t *testing.T) {
 		FullName: "Rehan",
 	}
 
-	resCP := []entity.CartProduct{
-		{
-			CartID:    uuid.New(),
-			ProductID: uuid.New(),
-			Quantity:  1,
-		},
-	}
-
 	resProduct := entity.Product{
 		ID:                uuid.New(),
 		Name:              "Makanan",
-		Price:             1000,
+		Price:             util.MoneyFromInt(1000),
 		Description:       "",
 		IsDiscount:        false,
-		DiscountValue:     sql.NullFloat64{},
+		DiscountValue:     util.NullMoney{},
 		StartDateDiscount: sql.NullTime{},
 		EndDateDiscount:   sql.NullTime{},
 	}
 
+	resCP := []entity.CartProduct{
+		{
+			CartID:    res.ID,
+			ProductID: resProduct.ID,
+			Quantity:  1,
+		},
+	}
+
 	ctx := context.TODO()
 	b := persistence.QueryBuilderCriteria{}
 	b.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"full_name": req.FullName}}}}
-	cartRepo.EXPECT().Get(ctx, &b).Return(res, nil)
+	cartRepo.EXPECT().Get(gomock.Any(), &b).Return(res, nil)
 
 	bc := persistence.QueryBuilderCriteria{}
 	bc.Where = &persistence.Where{}
@@ -60,16 +68,210 @@ func TestFindCart(t *testing.T) {
 	// bc.Join.LeftJoin = []string{"products ON products.id = cart_products.product_id"}
 	// bc.Where.And = append(bc.Where.And, squirrel.And{squirrel.ILike{"product_name": "%" + req.ProductName + "%"}})
 	bc.Where.And = append(bc.Where.And, squirrel.And{squirrel.Eq{"cart_id": res.ID}})
-	cartProductRepo.EXPECT().Find(ctx, &bc).Return(resCP, nil)
+	cartProductRepo.EXPECT().Find(gomock.Any(), &bc).Return(resCP, nil)
+
+	productRepo.EXPECT().FindByIDs(gomock.Any(), []uuid.UUID{resProduct.ID}).Return([]entity.Product{resProduct}, nil)
 
-	for _, val := range resCP {
-		bp := persistence.QueryBuilderCriteria{}
-		bp.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": val.ProductID}}}}
-		productRepo.EXPECT().Get(ctx, &bp).Return(resProduct, nil)
+	campaignTargetRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+
+	discountSvc := service.NewDiscountService(ctx, campaignRepo, campaignTargetRepo, nil)
+	ppn := entity.TaxRule{ID: uuid.New(), Name: "PPN", Rate: util.MoneyFromInt(11)}
+	taxRuleRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.TaxRule{ppn}, nil)
+
+	currencySvc := service.NewCurrencyService(exchangeRateRepo)
+	taxSvc := service.NewTaxService(taxRuleRepo)
+	addressSvc := service.NewAddressService(ctx, addressRepo)
+	cartSvc := service.NewCartService(
+		ctx, cartRepo, cartProductRepo, productRepo, discountSvc, currencySvc, taxSvc, addressSvc,
+		service.NewDefaultShippingCalculator(),
+	)
+	result, err := cartSvc.Find(ctx, &req)
+	assert.NoError(t, err)
+	assert.Equal(t, util.MoneyFromInt(1000).String(), result.SubTotal.String())
+	assert.Equal(t, util.MoneyFromInt(110).String(), result.TotalTax.String())
+	assert.Equal(t, util.MoneyFromInt(1110).String(), result.Total.String())
+	assert.Nil(t, result.Shipping)
+
+}
+
+func TestFindCartWithShipping(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cartRepo := mocks.NewMockCartRepository(ctrl)
+	cartProductRepo := mocks.NewMockCartProductRepository(ctrl)
+	productRepo := mocks.NewMockProductRepository(ctrl)
+	campaignRepo := mocks.NewMockDiscountCampaignRepository(ctrl)
+	campaignTargetRepo := mocks.NewMockDiscountCampaignTargetRepository(ctrl)
+	exchangeRateRepo := mocks.NewMockExchangeRateRepository(ctrl)
+	taxRuleRepo := mocks.NewMockTaxRuleRepository(ctrl)
+	addressRepo := mocks.NewMockAddressRepository(ctrl)
+
+	req := request.CartCriteria{
+		FullName:        "Rehan",
+		ShippingService: "regular",
 	}
 
-	cartSvc := service.NewCartService(ctx, cartRepo, cartProductRepo, productRepo)
-	_, err := cartSvc.Find(ctx, &req)
+	cart := entity.Cart{ID: uuid.New(), FullName: req.FullName}
+	product := entity.Product{
+		ID:       uuid.New(),
+		Name:     "Beras",
+		Price:    util.MoneyFromInt(1000),
+		Currency: util.DefaultCurrency,
+		Weight:   1500,
+	}
+	address := entity.Address{
+		ID:        uuid.New(),
+		FullName:  req.FullName,
+		City:      "Jakarta Selatan",
+		Province:  "DKI Jakarta",
+		Country:   "ID",
+		IsDefault: true,
+	}
+
+	ctx := context.TODO()
+	cartRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(cart, nil)
+	cartProductRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.CartProduct{{CartID: cart.ID, ProductID: product.ID, Quantity: 2}}, nil,
+	)
+	productRepo.EXPECT().FindByIDs(gomock.Any(), []uuid.UUID{product.ID}).Return([]entity.Product{product}, nil)
+	campaignTargetRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+	taxRuleRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.TaxRule{}, nil)
+
+	ab := persistence.QueryBuilderCriteria{}
+	ab.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"full_name": req.FullName}}, {squirrel.Eq{"is_default": true}}},
+	}
+	addressRepo.EXPECT().Get(gomock.Any(), &ab).Return(address, nil)
+
+	cartSvc := service.NewCartService(
+		ctx, cartRepo, cartProductRepo, productRepo, service.NewDiscountService(ctx, campaignRepo, campaignTargetRepo, nil),
+		service.NewCurrencyService(exchangeRateRepo), service.NewTaxService(taxRuleRepo),
+		service.NewAddressService(ctx, addressRepo), service.NewDefaultShippingCalculator(),
+	)
+	result, err := cartSvc.Find(ctx, &req)
 	assert.NoError(t, err)
 
+	// 3 kg started within jabodetabek: 10000 + 2 * 5000
+	assert.Equal(t, 3000, result.Weight)
+	assert.Equal(t, "jabodetabek", result.Shipping.Zone)
+	assert.Equal(t, "20000.00", result.Shipping.Cost.String())
+	assert.Equal(t, "22000.00", result.Total.String())
+}
+
+func TestPurgeCarts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	cartRepo := mocks.NewMockCartRepository(ctrl)
+
+	ctx := context.TODO()
+	cartRepo.EXPECT().DeleteCreatedBefore(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-48*time.Hour), before, time.Minute)
+			return 3, nil
+		},
+	)
+
+	cartSvc := service.NewCartService(ctx, cartRepo, nil, nil, nil, nil, nil, nil, nil)
+	deleted, err := cartSvc.Purge(ctx, 48*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	_, err = cartSvc.Purge(ctx, 0)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+// BenchmarkFindCart renders carts of a growing number of lines against repositories that wait for a simulated
+// database round-trip on every query. lookup=batched loads the products of the lines in one query, as Find
+// does, and both ns/op and queries/op stay flat as the cart grows. lookup=per-line simulates the former lookup
+// of the products line by line, one round-trip each, which makes them grow with the cart.
+func BenchmarkFindCart(b *testing.B) {
+	const roundTrip = 100 * time.Microsecond
+
+	for _, lookup := range []string{"batched", "per-line"} {
+		for _, lines := range []int{1, 10, 100} {
+			b.Run(fmt.Sprintf("lookup=%s/lines=%d", lookup, lines), func(b *testing.B) {
+				ctrl := gomock.NewController(b)
+				cartRepo := mocks.NewMockCartRepository(ctrl)
+				cartProductRepo := mocks.NewMockCartProductRepository(ctrl)
+				productRepo := mocks.NewMockProductRepository(ctrl)
+				campaignTargetRepo := mocks.NewMockDiscountCampaignTargetRepository(ctrl)
+				taxRuleRepo := mocks.NewMockTaxRuleRepository(ctrl)
+
+				cart := entity.Cart{ID: uuid.New(), FullName: "Rehan"}
+				products := make([]entity.Product, 0, lines)
+				cartProducts := make([]entity.CartProduct, 0, lines)
+				for i := 0; i < lines; i++ {
+					p := entity.Product{
+						ID: uuid.New(), Name: fmt.Sprintf("Makanan %d", i), Price: util.MoneyFromInt(1000),
+					}
+					products = append(products, p)
+					cartProducts = append(
+						cartProducts, entity.CartProduct{CartID: cart.ID, ProductID: p.ID, Quantity: 1},
+					)
+				}
+
+				queries := 0
+				query := func() {
+					queries++
+					time.Sleep(roundTrip)
+				}
+				cartRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(context.Context, *persistence.QueryBuilderCriteria) (entity.Cart, error) {
+						query()
+						return cart, nil
+					},
+				).AnyTimes()
+				cartProductRepo.EXPECT().Find(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(context.Context, *persistence.QueryBuilderCriteria) ([]entity.CartProduct, error) {
+						query()
+						return cartProducts, nil
+					},
+				).AnyTimes()
+				productRepo.EXPECT().FindByIDs(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, ids []uuid.UUID) ([]entity.Product, error) {
+						roundTrips := 1
+						if lookup == "per-line" {
+							// what a productRepo.Get per line costs
+							roundTrips = len(ids)
+						}
+						for i := 0; i < roundTrips; i++ {
+							query()
+						}
+						return products, nil
+					},
+				).AnyTimes()
+				campaignTargetRepo.EXPECT().Find(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(context.Context, *persistence.QueryBuilderCriteria) ([]entity.DiscountCampaignTarget, error) {
+						query()
+						return nil, nil
+					},
+				).AnyTimes()
+				taxRuleRepo.EXPECT().Find(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(context.Context, *persistence.QueryBuilderCriteria) ([]entity.TaxRule, error) {
+						query()
+						return nil, nil
+					},
+				).AnyTimes()
+
+				ctx := context.TODO()
+				cartSvc := service.NewCartService(
+					ctx, cartRepo, cartProductRepo, productRepo,
+					service.NewDiscountService(ctx, nil, campaignTargetRepo, nil),
+					service.NewCurrencyService(nil), service.NewTaxService(taxRuleRepo), nil,
+					service.NewDefaultShippingCalculator(),
+				)
+				req := request.CartCriteria{FullName: "Rehan"}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					res, err := cartSvc.Find(ctx, &req)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(res.Products) != lines {
+						b.Fatalf("got %d lines, want %d", len(res.Products), lines)
+					}
+				}
+				b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+			})
+		}
+	}
 }
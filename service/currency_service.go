@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type CurrencyService struct {
+	exchangeRateRepo persistence.ExchangeRateRepository
+}
+
+func NewCurrencyService(exchangeRateRepo persistence.ExchangeRateRepository) *CurrencyService {
+	return &CurrencyService{exchangeRateRepo: exchangeRateRepo}
+}
+
+func (s *CurrencyService) Find(ctx context.Context) (res []response.ExchangeRateResponse, err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyService.Find")
+	defer func() { tracing.End(span, err) }()
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Order = map[string]string{"base_currency": "ASC"}
+
+	results, err := s.exchangeRateRepo.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]response.ExchangeRateResponse, 0, len(results))
+	for _, val := range results {
+		res = append(
+			res, response.ExchangeRateResponse{
+				BaseCurrency:  val.BaseCurrency,
+				QuoteCurrency: val.QuoteCurrency,
+				Rate:          val.Rate,
+				UpdatedAt:     val.UpdatedAt,
+			},
+		)
+	}
+
+	return res, nil
+}
+
+// Store sets the rate from base to quote currency, replacing the previous one if any.
+func (s *CurrencyService) Store(ctx context.Context, req *request.ExchangeRateAddRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "CurrencyService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	base := strings.ToUpper(req.BaseCurrency)
+	quote := strings.ToUpper(req.QuoteCurrency)
+
+	fields := make([]util.FieldError, 0)
+	if !util.IsSupportedCurrency(base) {
+		fields = append(fields, util.FieldError{Field: "base_currency", Message: "currency isn't supported"})
+	}
+	if !util.IsSupportedCurrency(quote) {
+		fields = append(fields, util.FieldError{Field: "quote_currency", Message: "currency isn't supported"})
+	}
+	if base == quote {
+		fields = append(fields, util.FieldError{Field: "quote_currency", Message: "must differ from base currency"})
+	}
+	if !req.Rate.IsPositive() {
+		fields = append(fields, util.FieldError{Field: "rate", Message: "must be greater than 0"})
+	}
+	if err := util.NewValidationError(fields); err != nil {
+		return err
+	}
+
+	rate := entity.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          req.Rate,
+		UpdatedAt:     time.Now(),
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"base_currency": base}}, {squirrel.Eq{"quote_currency": quote}}},
+	}
+	_, err = s.exchangeRateRepo.Get(ctx, &builder)
+	if err != sql.ErrNoRows && err != nil {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		_, err = s.exchangeRateRepo.Store(ctx, &rate)
+	} else {
+		_, err = s.exchangeRateRepo.Update(ctx, &rate)
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Converter returns a converter into currency, an empty currency falls back to util.DefaultCurrency.
+// Rates are only loaded once an amount in another currency has to be converted.
+func (s *CurrencyService) Converter(currency string) (res *CurrencyConverter, err error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = util.DefaultCurrency
+	}
+
+	if !util.IsSupportedCurrency(currency) {
+		return res, util.NewValidationError(
+			[]util.FieldError{{Field: "currency", Message: "currency isn't supported"}},
+		)
+	}
+
+	return &CurrencyConverter{Currency: currency, exchangeRateRepo: s.exchangeRateRepo}, nil
+}
+
+// CurrencyConverter converts amounts into Currency with the rates stored in exchange_rates, either the rate
+// from the amount currency into Currency or the inverse of the rate the other way round.
+type CurrencyConverter struct {
+	Currency         string
+	exchangeRateRepo persistence.ExchangeRateRepository
+	rates            map[string]util.Rate
+}
+
+func (c *CurrencyConverter) Convert(ctx context.Context, amount util.Money, from string) (res util.Money, err error) {
+	if from == "" || from == c.Currency {
+		return amount.RoundTo(c.Currency), nil
+	}
+
+	if c.rates == nil {
+		if err := c.loadRates(ctx); err != nil {
+			return res, err
+		}
+	}
+
+	rate, ok := c.rates[from]
+	if !ok {
+		return res, &util.BadRequestError{Message: fmt.Sprintf("no exchange rate from %s to %s", from, c.Currency)}
+	}
+
+	return amount.Convert(rate, c.Currency), nil
+}
+
+func (c *CurrencyConverter) loadRates(ctx context.Context) error {
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		Or: []squirrel.Or{{squirrel.Eq{"quote_currency": c.Currency}, squirrel.Eq{"base_currency": c.Currency}}},
+	}
+
+	results, err := c.exchangeRateRepo.Find(ctx, &builder)
+	if err != nil {
+		return err
+	}
+
+	c.rates = make(map[string]util.Rate)
+	for _, val := range results {
+		if val.QuoteCurrency == c.Currency {
+			c.rates[val.BaseCurrency] = val.Rate
+		}
+	}
+
+	// a direct rate always wins over the inverse of the opposite one
+	for _, val := range results {
+		if _, ok := c.rates[val.QuoteCurrency]; val.BaseCurrency == c.Currency && !ok {
+			c.rates[val.QuoteCurrency] = val.Rate.Inverse()
+		}
+	}
+
+	return nil
+}
+
+// convertProductResponse converts every amount of a product into the converter currency. Percentage
+// discount values are left alone since they aren't amounts.
+func convertProductResponse(ctx context.Context, res *response.ProductResponse, conv *CurrencyConverter) (err error) {
+	from := res.Currency
+	if res.Price, err = conv.Convert(ctx, res.Price, from); err != nil {
+		return err
+	}
+	if res.DiscountAmount, err = conv.Convert(ctx, res.DiscountAmount, from); err != nil {
+		return err
+	}
+	if res.DiscountType != entity.DiscountTypePercentage {
+		if res.DiscountValue, err = conv.Convert(ctx, res.DiscountValue, from); err != nil {
+			return err
+		}
+	}
+
+	for i := range res.AppliedDiscounts {
+		if res.AppliedDiscounts[i].Amount, err = conv.Convert(ctx, res.AppliedDiscounts[i].Amount, from); err != nil {
+			return err
+		}
+	}
+
+	res.DiscountAmount = res.DiscountAmount.Min(res.Price)
+	res.FinalPrice = res.Price.Sub(res.DiscountAmount)
+	res.Currency = conv.Currency
+
+	return nil
+}
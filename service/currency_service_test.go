@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestConvertCurrency(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	exchangeRateMock := mocks.NewMockExchangeRateRepository(mockCtrl)
+
+	usdRate, err := util.RateFromString("0.0000655")
+	assert.NoError(t, err)
+	jpyRate, err := util.RateFromString("9.6")
+	assert.NoError(t, err)
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		Or: []squirrel.Or{{squirrel.Eq{"quote_currency": "USD"}, squirrel.Eq{"base_currency": "USD"}}},
+	}
+	exchangeRateMock.EXPECT().Find(gomock.Any(), &b).Return(
+		[]entity.ExchangeRate{
+			{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: usdRate},
+			{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: jpyRate},
+		}, nil,
+	)
+
+	currencySvc := service.NewCurrencyService(exchangeRateMock)
+	conv, err := currencySvc.Converter("usd")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", conv.Currency)
+
+	// 15000 * 0.0000655 = 0.9825
+	amount, err := conv.Convert(ctx, util.MoneyFromInt(15000), "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.98", amount.String())
+
+	// converted with the inverse of the USD to JPY rate, 1000 / 9.6 = 104.1666...
+	amount, err = conv.Convert(ctx, util.MoneyFromInt(1000), "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, "104.17", amount.String())
+
+	_, err = conv.Convert(ctx, util.MoneyFromInt(1000), "EUR")
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestConvertCurrencyRoundsToMinorUnit(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	exchangeRateMock := mocks.NewMockExchangeRateRepository(mockCtrl)
+
+	jpyRate, err := util.RateFromString("0.0094")
+	assert.NoError(t, err)
+	exchangeRateMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.ExchangeRate{{BaseCurrency: "IDR", QuoteCurrency: "JPY", Rate: jpyRate}}, nil,
+	)
+
+	currencySvc := service.NewCurrencyService(exchangeRateMock)
+	conv, err := currencySvc.Converter("JPY")
+	assert.NoError(t, err)
+
+	// 15050 * 0.0094 = 141.47 yen, yen has no minor unit
+	amount, err := conv.Convert(ctx, util.MoneyFromInt(15050), "IDR")
+	assert.NoError(t, err)
+	assert.Equal(t, "141", amount.String())
+}
+
+func TestConverterUnsupportedCurrency(t *testing.T) {
+	currencySvc := service.NewCurrencyService(nil)
+
+	_, err := currencySvc.Converter("XYZ")
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestStoreExchangeRate(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	exchangeRateMock := mocks.NewMockExchangeRateRepository(mockCtrl)
+
+	rate, err := util.RateFromString("0.0000655")
+	assert.NoError(t, err)
+	req := request.ExchangeRateAddRequest{BaseCurrency: "idr", QuoteCurrency: "usd", Rate: rate}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"base_currency": "IDR"}}, {squirrel.Eq{"quote_currency": "USD"}}},
+	}
+	exchangeRateMock.EXPECT().Get(gomock.Any(), &b).Return(entity.ExchangeRate{}, sql.ErrNoRows)
+	exchangeRateMock.EXPECT().Store(gomock.Any(), gomock.Any()).Return(entity.ExchangeRate{}, nil)
+
+	currencySvc := service.NewCurrencyService(exchangeRateMock)
+	err = currencySvc.Store(ctx, &req)
+	assert.NoError(t, err)
+}
+
+func TestStoreExchangeRateError(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	exchangeRateMock := mocks.NewMockExchangeRateRepository(mockCtrl)
+
+	rate, err := util.RateFromString("0.0000655")
+	assert.NoError(t, err)
+	req := request.ExchangeRateAddRequest{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: rate}
+
+	exchangeRateMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.ExchangeRate{}, nil)
+	exchangeRateMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(entity.ExchangeRate{}, errors.New("something wrong"))
+
+	currencySvc := service.NewCurrencyService(exchangeRateMock)
+	err = currencySvc.Store(ctx, &req)
+	assert.Error(t, err)
+}
+
+func TestStoreExchangeRateInvalid(t *testing.T) {
+	currencySvc := service.NewCurrencyService(nil)
+
+	req := request.ExchangeRateAddRequest{BaseCurrency: "IDR", QuoteCurrency: "IDR"}
+	err := currencySvc.Store(context.TODO(), &req)
+	badRequest, ok := err.(*util.BadRequestError)
+	assert.True(t, ok)
+	assert.Len(t, badRequest.Fields, 2)
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type DiscountService struct {
+	ctx          context.Context
+	campaignRepo persistence.DiscountCampaignRepository
+	targetRepo   persistence.DiscountCampaignTargetRepository
+	currencySvc  *CurrencyService
+}
+
+// AppliedDiscount is the discount picked for a single unit of a product.
+type AppliedDiscount struct {
+	Amount util.Money
+	Lines  []AppliedDiscountLine
+}
+
+type AppliedDiscountLine struct {
+	Campaign entity.DiscountCampaign
+	Amount   util.Money
+}
+
+func NewDiscountService(
+	ctx context.Context, campaignRepo persistence.DiscountCampaignRepository,
+	targetRepo persistence.DiscountCampaignTargetRepository, currencySvc *CurrencyService,
+) *DiscountService {
+	return &DiscountService{
+		ctx: ctx, campaignRepo: campaignRepo, targetRepo: targetRepo, currencySvc: currencySvc,
+	}
+}
+
+func (s *DiscountService) Find(ctx context.Context, req *request.DiscountCampaignCriteria) (
+	res *util.PaginationResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "DiscountService.Find")
+	defer func() { tracing.End(span, err) }()
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{}
+
+	if req.Search != "" {
+		and := squirrel.And{squirrel.ILike{"name": "%" + req.Search + "%"}}
+		builder.Where.And = append(builder.Where.And, and)
+	}
+
+	page := uint64(req.Page)
+	limit := uint64(req.Limit)
+	offset := (page - 1) * limit
+
+	builder.Limit = &limit
+	builder.Offset = &offset
+	responses := make([]response.DiscountCampaignResponse, 0)
+
+	results, err := s.campaignRepo.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	for _, val := range results {
+		responses = append(
+			responses, response.DiscountCampaignResponse{
+				ID:           val.ID,
+				Name:         val.Name,
+				DiscountType: val.DiscountType,
+				Value:        campaignValue(val),
+				Currency:     val.Currency,
+				Priority:     val.Priority,
+				IsStackable:  val.IsStackable,
+				StartDate:    val.StartDate,
+				EndDate:      val.EndDate,
+			},
+		)
+	}
+
+	totalRow, err := s.campaignRepo.Count(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	return util.BuildPagination(req.Pagination, responses, totalRow), nil
+}
+
+func (s *DiscountService) Store(ctx context.Context, req *request.DiscountCampaignAddRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "DiscountService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	fields := make([]util.FieldError, 0)
+	if len(req.ProductIDs) == 0 && len(req.Categories) == 0 {
+		fields = append(
+			fields, util.FieldError{Field: "product_ids", Message: "must target at least one product or category"},
+		)
+	}
+
+	fields = validateDiscountValue("discount_type", "value", req.DiscountType, req.Value, util.Money{}, fields)
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = util.DefaultCurrency
+	}
+	if !util.IsSupportedCurrency(currency) {
+		fields = append(fields, util.FieldError{Field: "currency", Message: "currency isn't supported"})
+	}
+	startDate, fields := parseDateField("start_date", req.StartDate, fields)
+	endDate, fields := parseDateField("end_date", req.EndDate, fields)
+	fields = validateDateRange("end_date", startDate, endDate, fields)
+	if err := util.NewValidationError(fields); err != nil {
+		return err
+	}
+
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	campaign := entity.DiscountCampaign{
+		Name:         req.Name,
+		DiscountType: req.DiscountType,
+		Value:        req.Value,
+		Currency:     currency,
+		Priority:     req.Priority,
+		IsStackable:  req.IsStackable,
+		StartDate:    startDate,
+		EndDate:      endDate,
+	}
+	campaign.Value = campaignValue(campaign)
+	campaign, err = s.campaignRepo.WithTx(tx).Store(ctx, &campaign)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return err
+	}
+
+	targets := make([]entity.DiscountCampaignTarget, 0, len(req.ProductIDs)+len(req.Categories))
+	for _, productID := range req.ProductIDs {
+		targets = append(
+			targets, entity.DiscountCampaignTarget{
+				CampaignID: campaign.ID,
+				ProductID:  uuid.NullUUID{UUID: productID, Valid: true},
+			},
+		)
+	}
+	for _, category := range req.Categories {
+		targets = append(
+			targets, entity.DiscountCampaignTarget{
+				CampaignID: campaign.ID,
+				Category:   sql.NullString{String: category, Valid: true},
+			},
+		)
+	}
+
+	targetTx := s.targetRepo.WithTx(tx)
+	for i := range targets {
+		_, err = targetTx.Store(ctx, &targets[i])
+		if err != nil {
+			if err := tx.Rollback(); err != nil {
+				slog.WarnContext(ctx, "rollback failed", "error", err)
+			}
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// BestDiscounts returns the best applicable discount per product id for the day at.
+// Products without any applicable discount are left out of the result.
+func (s *DiscountService) BestDiscounts(ctx context.Context, products []entity.Product, at time.Time) (
+	res map[uuid.UUID]AppliedDiscount, err error,
+) {
+	ctx, span := tracing.Start(ctx, "DiscountService.BestDiscounts")
+	defer func() { tracing.End(span, err) }()
+
+	res = make(map[uuid.UUID]AppliedDiscount)
+	if len(products) == 0 {
+		return res, nil
+	}
+
+	productIDs := make([]uuid.UUID, 0, len(products))
+	categories := make([]string, 0)
+	for _, p := range products {
+		productIDs = append(productIDs, p.ID)
+		if p.Category.Valid {
+			categories = append(categories, p.Category.String)
+		}
+	}
+
+	tBuilder := persistence.QueryBuilderCriteria{}
+	tBuilder.Where = &persistence.Where{
+		Or: []squirrel.Or{
+			{squirrel.Eq{"product_id": productIDs}, squirrel.Eq{"category": categories}},
+		},
+	}
+	targets, err := s.targetRepo.Find(ctx, &tBuilder)
+	if err != nil {
+		return res, err
+	}
+
+	campaigns := make(map[uuid.UUID]entity.DiscountCampaign)
+	if len(targets) > 0 {
+		campaignIDs := make([]uuid.UUID, 0, len(targets))
+		for _, t := range targets {
+			campaignIDs = append(campaignIDs, t.CampaignID)
+		}
+
+		day := at.Format(dateLayout)
+		cBuilder := persistence.QueryBuilderCriteria{}
+		cBuilder.Where = &persistence.Where{
+			And: []squirrel.And{
+				{squirrel.Eq{"id": campaignIDs}},
+				{squirrel.LtOrEq{"start_date": day}},
+				{squirrel.GtOrEq{"end_date": day}},
+			},
+		}
+		results, err := s.campaignRepo.Find(ctx, &cBuilder)
+		if err != nil {
+			return res, err
+		}
+
+		for _, c := range results {
+			campaigns[c.ID] = c
+		}
+	}
+
+	converters := make(map[string]*CurrencyConverter)
+	for _, p := range products {
+		candidates := make([]entity.DiscountCampaign, 0)
+		if own, ok := productDiscount(p); ok {
+			candidates = append(candidates, own)
+		}
+
+		for _, t := range targets {
+			c, ok := campaigns[t.CampaignID]
+			if !ok {
+				continue
+			}
+
+			byProduct := t.ProductID.Valid && t.ProductID.UUID == p.ID
+			byCategory := t.Category.Valid && p.Category.Valid && t.Category.String == p.Category.String
+			if !byProduct && !byCategory {
+				continue
+			}
+
+			if c.DiscountType == entity.DiscountTypeFixed && c.Currency != p.Currency {
+				conv, ok := converters[p.Currency]
+				if !ok {
+					if conv, err = s.currencySvc.Converter(p.Currency); err != nil {
+						return res, err
+					}
+					converters[p.Currency] = conv
+				}
+				if c.Value, err = conv.Convert(ctx, c.Value, c.Currency); err != nil {
+					return res, err
+				}
+				c.Currency = p.Currency
+			}
+			candidates = append(candidates, c)
+		}
+
+		// percentages are then rounded to the minor unit of the product currency
+		applied := bestDiscount(p.Price.RoundTo(p.Currency), candidates, at)
+		if applied.Amount.IsPositive() {
+			res[p.ID] = applied
+		}
+	}
+
+	return res, nil
+}
+
+// campaignValue returns the value of c, a fixed amount being rounded to the currency of c.
+func campaignValue(c entity.DiscountCampaign) util.Money {
+	if c.DiscountType == entity.DiscountTypeFixed {
+		return c.Value.RoundTo(c.Currency)
+	}
+
+	return c.Value
+}
+
+// productDiscount turns the discount stored on the product itself into a campaign, so it competes
+// with the other campaigns as a non stackable one.
+func productDiscount(p entity.Product) (res entity.DiscountCampaign, ok bool) {
+	if !p.IsDiscount || !p.DiscountValue.Valid || !p.StartDateDiscount.Valid || !p.EndDateDiscount.Valid {
+		return res, false
+	}
+
+	// products stored before discount types existed always meant a fixed amount
+	discountType := entity.DiscountTypeFixed
+	if p.DiscountType.Valid {
+		discountType = p.DiscountType.String
+	}
+
+	return entity.DiscountCampaign{
+		Name:         "product discount",
+		DiscountType: discountType,
+		Value:        p.DiscountValue.Money,
+		Currency:     p.Currency,
+		StartDate:    p.StartDateDiscount.Time,
+		EndDate:      p.EndDateDiscount.Time,
+	}, true
+}
+
+// bestDiscount picks the biggest discount out of the candidates active at the given day:
+//   - a non stackable campaign can only be applied on its own,
+//   - all stackable campaigns are applied together, by priority, each one on the price left by the previous one,
+//   - on equal amounts the higher priority non stackable campaign wins over lower ones and over the stack.
+func bestDiscount(price util.Money, candidates []entity.DiscountCampaign, at time.Time) (res AppliedDiscount) {
+	sort.SliceStable(
+		candidates, func(i, j int) bool {
+			return candidates[i].Priority > candidates[j].Priority
+		},
+	)
+
+	stacked := AppliedDiscount{}
+	remaining := price
+	for _, c := range candidates {
+		if !c.IsActive(at) {
+			continue
+		}
+
+		if c.IsStackable {
+			amount := c.Amount(remaining)
+			remaining = remaining.Sub(amount)
+			stacked.Amount = stacked.Amount.Add(amount)
+			stacked.Lines = append(stacked.Lines, AppliedDiscountLine{Campaign: c, Amount: amount})
+			continue
+		}
+
+		if amount := c.Amount(price); amount.GreaterThan(res.Amount) {
+			res = AppliedDiscount{Amount: amount, Lines: []AppliedDiscountLine{{Campaign: c, Amount: amount}}}
+		}
+	}
+
+	if stacked.Amount.GreaterThan(res.Amount) {
+		return stacked
+	}
+
+	return res
+}
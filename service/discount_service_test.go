@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+	"time"
+)
+
+func TestBestDiscountsStackable(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+
+	now := time.Now()
+	product := entity.Product{
+		ID:       uuid.New(),
+		Name:     "Makanan",
+		Price:    util.MoneyFromInt(10000),
+		Category: sql.NullString{String: "food", Valid: true},
+	}
+
+	weekend := entity.DiscountCampaign{
+		ID: uuid.New(), Name: "weekend", DiscountType: entity.DiscountTypePercentage, Value: util.MoneyFromInt(10),
+		Priority: 1, IsStackable: true, StartDate: now, EndDate: now,
+	}
+	member := entity.DiscountCampaign{
+		ID: uuid.New(), Name: "member", DiscountType: entity.DiscountTypePercentage, Value: util.MoneyFromInt(5),
+		Priority: 2, IsStackable: true, StartDate: now, EndDate: now,
+	}
+	monthly := entity.DiscountCampaign{
+		ID: uuid.New(), Name: "monthly", DiscountType: entity.DiscountTypeFixed, Value: util.MoneyFromInt(1200),
+		StartDate: now.AddDate(0, 0, -10), EndDate: now.AddDate(0, 0, 10),
+	}
+	targets := []entity.DiscountCampaignTarget{
+		{CampaignID: weekend.ID, Category: sql.NullString{String: "food", Valid: true}},
+		{CampaignID: member.ID, ProductID: uuid.NullUUID{UUID: product.ID, Valid: true}},
+		{CampaignID: monthly.ID, ProductID: uuid.NullUUID{UUID: product.ID, Valid: true}},
+	}
+
+	tb := persistence.QueryBuilderCriteria{}
+	tb.Where = &persistence.Where{
+		Or: []squirrel.Or{
+			{squirrel.Eq{"product_id": []uuid.UUID{product.ID}}, squirrel.Eq{"category": []string{"food"}}},
+		},
+	}
+	campaignTargetMock.EXPECT().Find(gomock.Any(), &tb).Return(targets, nil)
+	campaignMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaign{weekend, member, monthly}, nil)
+
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	res, err := discountSvc.BestDiscounts(ctx, []entity.Product{product}, now)
+	assert.NoError(t, err)
+
+	// member first: 5% of 10000, then weekend: 10% of the remaining 9500
+	applied := res[product.ID]
+	assert.Equal(t, util.MoneyFromInt(1450).String(), applied.Amount.String())
+	assert.Len(t, applied.Lines, 2)
+	assert.Equal(t, member.ID, applied.Lines[0].Campaign.ID)
+	assert.Equal(t, weekend.ID, applied.Lines[1].Campaign.ID)
+}
+
+func TestBestDiscountsExclusive(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+
+	now := time.Now()
+	product := entity.Product{
+		ID:                uuid.New(),
+		Name:              "Makanan",
+		Price:             util.MoneyFromInt(10000),
+		IsDiscount:        true,
+		DiscountValue:     util.NullMoney{Money: util.MoneyFromInt(500), Valid: true},
+		StartDateDiscount: sql.NullTime{Time: now, Valid: true},
+		EndDateDiscount:   sql.NullTime{Time: now, Valid: true},
+	}
+
+	flash := entity.DiscountCampaign{
+		ID: uuid.New(), Name: "flash sale", DiscountType: entity.DiscountTypeFixed, Value: util.MoneyFromInt(15000),
+		StartDate: now, EndDate: now,
+	}
+	targets := []entity.DiscountCampaignTarget{
+		{CampaignID: flash.ID, ProductID: uuid.NullUUID{UUID: product.ID, Valid: true}},
+	}
+
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(targets, nil)
+	campaignMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaign{flash}, nil)
+
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	res, err := discountSvc.BestDiscounts(ctx, []entity.Product{product}, now)
+	assert.NoError(t, err)
+
+	// the flash sale beats the product discount but can't take the price below zero
+	applied := res[product.ID]
+	assert.Equal(t, util.MoneyFromInt(10000).String(), applied.Amount.String())
+	assert.Len(t, applied.Lines, 1)
+	assert.Equal(t, flash.ID, applied.Lines[0].Campaign.ID)
+}
+
+func TestBestDiscountsConvertsFixedAmounts(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+	exchangeRateMock := mocks.NewMockExchangeRateRepository(mockCtrl)
+
+	now := time.Now()
+	product := entity.Product{ID: uuid.New(), Name: "Kopi", Price: util.MoneyFromInt(20), Currency: "USD"}
+	idr := entity.DiscountCampaign{
+		ID: uuid.New(), Name: "rupiah off", DiscountType: entity.DiscountTypeFixed, Value: util.MoneyFromInt(80000),
+		Currency: "IDR", StartDate: now, EndDate: now,
+	}
+	targets := []entity.DiscountCampaignTarget{
+		{CampaignID: idr.ID, ProductID: uuid.NullUUID{UUID: product.ID, Valid: true}},
+	}
+
+	rate, err := util.RateFromString("0.00006")
+	assert.NoError(t, err)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(targets, nil)
+	campaignMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaign{idr}, nil)
+	exchangeRateMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.ExchangeRate{{BaseCurrency: "IDR", QuoteCurrency: "USD", Rate: rate}}, nil,
+	)
+
+	currencySvc := service.NewCurrencyService(exchangeRateMock)
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, currencySvc)
+	res, err := discountSvc.BestDiscounts(ctx, []entity.Product{product}, now)
+	assert.NoError(t, err)
+
+	// 80000 IDR is 4.80 USD, not 80000 USD capped at the price
+	assert.Equal(t, "4.80", res[product.ID].Amount.String())
+}
+
+func TestBestDiscountsError(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("something wrong"))
+
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	products := []entity.Product{{ID: uuid.New(), Price: util.MoneyFromInt(1000)}}
+	_, err := discountSvc.BestDiscounts(ctx, products, time.Now())
+	assert.Error(t, err)
+}
+
+func TestStoreDiscountCampaignInvalidDate(t *testing.T) {
+	discountSvc := service.NewDiscountService(context.TODO(), nil, nil, nil)
+
+	req := request.DiscountCampaignAddRequest{
+		Name:         "weekend",
+		DiscountType: entity.DiscountTypePercentage,
+		Value:        util.MoneyFromInt(10),
+		StartDate:    "2022-08-30",
+		EndDate:      "2022-08-24",
+		Categories:   []string{"food"},
+	}
+
+	err := discountSvc.Store(context.TODO(), &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestStoreDiscountCampaignWithoutTarget(t *testing.T) {
+	discountSvc := service.NewDiscountService(context.TODO(), nil, nil, nil)
+
+	req := request.DiscountCampaignAddRequest{
+		Name:         "weekend",
+		DiscountType: entity.DiscountTypePercentage,
+		Value:        util.MoneyFromInt(10),
+		StartDate:    "2022-08-24",
+		EndDate:      "2022-08-30",
+	}
+
+	err := discountSvc.Store(context.TODO(), &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// FileStorage keeps the content of the files recorded in the files table, a file is addressed by its bucket
+// and location.
+type FileStorage interface {
+	Put(ctx context.Context, bucket, name string, data []byte) (location string, err error)
+	Get(ctx context.Context, bucket, location string) (data []byte, err error)
+}
+
+// LocalFileStorage stores files on disk, a bucket is a directory under Dir.
+type LocalFileStorage struct {
+	Dir string
+}
+
+func NewLocalFileStorage(dir string) *LocalFileStorage {
+	return &LocalFileStorage{Dir: dir}
+}
+
+func (s *LocalFileStorage) Put(ctx context.Context, bucket, name string, data []byte) (location string, err error) {
+	dir := filepath.Join(s.Dir, bucket)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return location, err
+	}
+
+	location = filepath.Base(name)
+	if err := os.WriteFile(filepath.Join(dir, location), data, 0o644); err != nil {
+		return "", err
+	}
+
+	return location, nil
+}
+
+func (s *LocalFileStorage) Get(ctx context.Context, bucket, location string) (data []byte, err error) {
+	return os.ReadFile(filepath.Join(s.Dir, bucket, filepath.Base(location)))
+}
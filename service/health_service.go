@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"interview-telkom-6/repository/migration"
+	"interview-telkom-6/response"
+	"time"
+)
+
+// healthCheckTimeout bounds each readiness check so a stuck database fails the probe instead of hanging it.
+const healthCheckTimeout = 2 * time.Second
+
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type MigrationChecker interface {
+	Pending(ctx context.Context) (res []migration.Migration, err error)
+}
+
+// HealthService answers the liveness and readiness probes of the orchestrator.
+type HealthService struct {
+	db         Pinger
+	migrations MigrationChecker
+}
+
+func NewHealthService(db Pinger, migrations MigrationChecker) *HealthService {
+	return &HealthService{db: db, migrations: migrations}
+}
+
+// Ready checks the database is reachable and every migration applied. ok is false when any check failed,
+// res holding the outcome of each of them.
+func (s *HealthService) Ready(ctx context.Context) (res response.HealthResponse, ok bool) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	res = response.HealthResponse{Status: "ok", Checks: map[string]string{}}
+	if err := s.db.PingContext(ctx); err != nil {
+		res.Checks["database"] = err.Error()
+	} else {
+		res.Checks["database"] = "ok"
+
+		pending, err := s.migrations.Pending(ctx)
+		switch {
+		case err != nil:
+			res.Checks["migrations"] = err.Error()
+		case len(pending) > 0:
+			res.Checks["migrations"] = fmt.Sprintf("%d pending", len(pending))
+		default:
+			res.Checks["migrations"] = "ok"
+		}
+	}
+
+	for _, val := range res.Checks {
+		if val != "ok" {
+			res.Status = "unavailable"
+			return res, false
+		}
+	}
+
+	return res, true
+}
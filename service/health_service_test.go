@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/repository/migration"
+	"interview-telkom-6/service"
+	"testing"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+type fakeMigrationChecker struct {
+	pending []migration.Migration
+}
+
+func (m fakeMigrationChecker) Pending(ctx context.Context) ([]migration.Migration, error) {
+	return m.pending, nil
+}
+
+func TestReady(t *testing.T) {
+	healthSvc := service.NewHealthService(fakePinger{}, fakeMigrationChecker{})
+
+	res, ok := healthSvc.Ready(context.TODO())
+	assert.True(t, ok)
+	assert.Equal(t, "ok", res.Status)
+	assert.Equal(t, "ok", res.Checks["database"])
+	assert.Equal(t, "ok", res.Checks["migrations"])
+}
+
+func TestReadyDatabaseDown(t *testing.T) {
+	healthSvc := service.NewHealthService(fakePinger{err: errors.New("connection refused")}, fakeMigrationChecker{})
+
+	res, ok := healthSvc.Ready(context.TODO())
+	assert.False(t, ok)
+	assert.Equal(t, "unavailable", res.Status)
+	assert.Equal(t, "connection refused", res.Checks["database"])
+}
+
+func TestReadyPendingMigrations(t *testing.T) {
+	healthSvc := service.NewHealthService(
+		fakePinger{}, fakeMigrationChecker{pending: []migration.Migration{{Version: 2, Name: "next"}}},
+	)
+
+	res, ok := healthSvc.Ready(context.TODO())
+	assert.False(t, ok)
+	assert.Equal(t, "1 pending", res.Checks["migrations"])
+}
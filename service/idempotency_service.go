@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"net/http"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// maxIdempotencyKeyLength matches the key column.
+const maxIdempotencyKeyLength = 255
+
+type IdempotencyService struct {
+	keyRepo persistence.IdempotencyKeyRepository
+	// claimTimeout is how long a request holds its key before a retry can claim it again.
+	claimTimeout time.Duration
+}
+
+func NewIdempotencyService(
+	keyRepo persistence.IdempotencyKeyRepository, claimTimeout time.Duration,
+) *IdempotencyService {
+	return &IdempotencyService{keyRepo: keyRepo, claimTimeout: claimTimeout}
+}
+
+// Begin claims key for the request hashing to hash. It returns nil when the request is the first one with key,
+// the caller then serves it and calls Finish, or the recorded response when the same request was already
+// served. Reusing key for another request is an UnprocessableEntityError, sending it again while the first
+// request is being served a ConflictError. A request that held key for longer than the claim timeout without
+// finishing is considered dead and its retry claims key again.
+func (s *IdempotencyService) Begin(ctx context.Context, key string, hash string) (
+	res *entity.IdempotencyKey, err error,
+) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin")
+	defer func() { tracing.End(span, err) }()
+
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return nil, util.NewValidationError(
+			[]util.FieldError{{Field: "Idempotency-Key", Message: "must be between 1 and 255 characters"}},
+		)
+	}
+
+	stored, err := s.keyRepo.StoreIfAbsent(ctx, &entity.IdempotencyKey{Key: key, RequestHash: hash})
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		return nil, nil
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"key": key}}}}
+	previous, err := s.keyRepo.Get(ctx, &builder)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the first request failed and released the key in between, this one can claim it
+		stored, err = s.keyRepo.StoreIfAbsent(ctx, &entity.IdempotencyKey{Key: key, RequestHash: hash})
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			return nil, nil
+		}
+		return nil, &util.ConflictError{Message: "a request with this idempotency key is in progress"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.RequestHash != hash {
+		return nil, &util.UnprocessableEntityError{Message: "idempotency key already used for another request"}
+	}
+	if !previous.Status.Valid {
+		reclaimed, err := s.keyRepo.Reclaim(
+			ctx, &entity.IdempotencyKey{Key: key, RequestHash: hash}, time.Now().Add(-s.claimTimeout),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if reclaimed {
+			return nil, nil
+		}
+
+		return nil, &util.ConflictError{Message: "a request with this idempotency key is in progress"}
+	}
+
+	return &previous, nil
+}
+
+// Finish records the response of the request that claimed key, for its retries to get it back. A response a
+// retry may change, see retryableStatus, isn't recorded, it releases key instead so that a retry is served
+// again.
+func (s *IdempotencyService) Finish(ctx context.Context, key string, status int, contentType string, body []byte) (
+	err error,
+) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Finish")
+	defer func() { tracing.End(span, err) }()
+
+	data := entity.IdempotencyKey{Key: key}
+	if retryableStatus(status) {
+		return s.keyRepo.Delete(ctx, &data)
+	}
+
+	data.Status = sql.NullInt64{Int64: int64(status), Valid: true}
+	data.ContentType = sql.NullString{String: contentType, Valid: contentType != ""}
+	data.Body = body
+	_, err = s.keyRepo.Update(ctx, &data)
+
+	return err
+}
+
+// retryableStatus tells whether a response with status reports a transient failure, such as a conflict with a
+// concurrent update, rather than the outcome of the request.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+
+	return status >= http.StatusInternalServerError
+}
+
+// Purge deletes the keys recorded more than olderThan ago, their requests being served again if sent again.
+func (s *IdempotencyService) Purge(ctx context.Context, olderThan time.Duration) (deleted int64, err error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Purge")
+	defer func() { tracing.End(span, err) }()
+
+	if olderThan <= 0 {
+		return 0, util.NewValidationError([]util.FieldError{{Field: "older_than", Message: "must be positive"}})
+	}
+
+	deleted, err = s.keyRepo.DeleteCreatedBefore(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
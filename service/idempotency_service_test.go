@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyBegin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	idempotencySvc := service.NewIdempotencyService(keyRepo, time.Minute)
+	ctx := context.TODO()
+
+	first := &entity.IdempotencyKey{Key: "key-1", RequestHash: "hash"}
+	keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), first).Return(true, nil)
+	res, err := idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	served := entity.IdempotencyKey{
+		Key:         "key-1",
+		RequestHash: "hash",
+		Status:      sql.NullInt64{Int64: http.StatusOK, Valid: true},
+		Body:        []byte(`{"status":"success"}`),
+	}
+	keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil).Times(3)
+	keyRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(served, nil).Times(2)
+
+	res, err = idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, &served, res)
+
+	_, err = idempotencySvc.Begin(ctx, "key-1", "other-hash")
+	assert.IsType(t, &util.UnprocessableEntityError{}, err)
+
+	keyRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(*first, nil)
+	keyRepo.EXPECT().Reclaim(gomock.Any(), first, gomock.Any()).Return(false, nil)
+	_, err = idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.IsType(t, &util.ConflictError{}, err)
+
+	_, err = idempotencySvc.Begin(ctx, strings.Repeat("k", 256), "hash")
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestIdempotencyBeginExpiredClaim(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	idempotencySvc := service.NewIdempotencyService(keyRepo, time.Minute)
+	ctx := context.TODO()
+
+	// the request holding the key died without recording a response
+	claim := entity.IdempotencyKey{Key: "key-1", RequestHash: "hash"}
+	keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil)
+	keyRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(claim, nil)
+	keyRepo.EXPECT().Reclaim(gomock.Any(), &claim, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.IdempotencyKey, before time.Time) (bool, error) {
+			assert.WithinDuration(t, time.Now().Add(-time.Minute), before, time.Second)
+			return true, nil
+		},
+	)
+	res, err := idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestIdempotencyBeginReleasedKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	idempotencySvc := service.NewIdempotencyService(keyRepo, time.Minute)
+	ctx := context.TODO()
+
+	// the first request released the key between the insert and the read, the retry claims it
+	gomock.InOrder(
+		keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil),
+		keyRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.IdempotencyKey{}, sql.ErrNoRows),
+		keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(true, nil),
+	)
+	res, err := idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	// another request claimed it first
+	gomock.InOrder(
+		keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil),
+		keyRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.IdempotencyKey{}, sql.ErrNoRows),
+		keyRepo.EXPECT().StoreIfAbsent(gomock.Any(), gomock.Any()).Return(false, nil),
+	)
+	_, err = idempotencySvc.Begin(ctx, "key-1", "hash")
+	assert.IsType(t, &util.ConflictError{}, err)
+}
+
+func TestIdempotencyFinish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	idempotencySvc := service.NewIdempotencyService(keyRepo, time.Minute)
+	ctx := context.TODO()
+
+	body := []byte(`{"status":"success"}`)
+	keyRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.IdempotencyKey) (entity.IdempotencyKey, error) {
+			assert.Equal(t, "key-1", data.Key)
+			assert.Equal(t, int64(http.StatusBadRequest), data.Status.Int64)
+			assert.Equal(t, "application/json; charset=utf-8", data.ContentType.String)
+			assert.Equal(t, body, data.Body)
+			return *data, nil
+		},
+	)
+	assert.NoError(
+		t, idempotencySvc.Finish(ctx, "key-1", http.StatusBadRequest, "application/json; charset=utf-8", body),
+	)
+
+	// a failure isn't replayed, the key is released for the retry
+	keyRepo.EXPECT().Delete(gomock.Any(), &entity.IdempotencyKey{Key: "key-2"}).Return(nil)
+	assert.NoError(t, idempotencySvc.Finish(ctx, "key-2", http.StatusInternalServerError, "", nil))
+
+	// neither is a conflict with a concurrent request
+	keyRepo.EXPECT().Delete(gomock.Any(), &entity.IdempotencyKey{Key: "key-3"}).Return(nil)
+	assert.NoError(t, idempotencySvc.Finish(ctx, "key-3", http.StatusConflict, "application/json", body))
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	keyRepo := mocks.NewMockIdempotencyKeyRepository(ctrl)
+	idempotencySvc := service.NewIdempotencyService(keyRepo, time.Minute)
+	ctx := context.TODO()
+
+	keyRepo.EXPECT().DeleteCreatedBefore(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+			return 2, nil
+		},
+	)
+	deleted, err := idempotencySvc.Purge(ctx, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	_, err = idempotencySvc.Purge(ctx, 0)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// InvoiceBucket is the bucket of the files table invoices are stored in.
+const InvoiceBucket = "invoices"
+
+// InvoiceService renders the invoice of a payment as a PDF from the snapshot of the cart the payment was
+// priced from, and keeps it in the files table.
+type InvoiceService struct {
+	fileRepo persistence.FileRepository
+	storage  FileStorage
+}
+
+func NewInvoiceService(fileRepo persistence.FileRepository, storage FileStorage) *InvoiceService {
+	return &InvoiceService{fileRepo: fileRepo, storage: storage}
+}
+
+// Generate renders the invoice of payment from its snapshot, stores it and returns it.
+func (s *InvoiceService) Generate(ctx context.Context, payment entity.Payment) (
+	name string, data []byte, err error,
+) {
+	ctx, span := tracing.Start(ctx, "InvoiceService.Generate")
+	defer func() { tracing.End(span, err) }()
+
+	if payment.Snapshot == nil {
+		return name, data, &util.NotFoundError{Message: "invoice not found"}
+	}
+
+	data, err = renderInvoice(payment.ID, payment.CreatedAt, payment.Snapshot)
+	if err != nil {
+		return name, data, err
+	}
+
+	name = invoiceFileName(payment.ID)
+	location, err := s.storage.Put(ctx, InvoiceBucket, name, data)
+	if err != nil {
+		return name, data, err
+	}
+
+	file := entity.File{Name: name, Location: location, BucketName: InvoiceBucket}
+	_, err = s.fileRepo.Store(ctx, &file)
+	if err != nil {
+		return name, data, err
+	}
+
+	return name, data, nil
+}
+
+// Get returns the stored invoice of the payment paymentID.
+func (s *InvoiceService) Get(ctx context.Context, paymentID uuid.UUID) (name string, data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "InvoiceService.Get")
+	defer func() { tracing.End(span, err) }()
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"bucket_name": InvoiceBucket}}, {squirrel.Eq{"name": invoiceFileName(paymentID)}},
+		},
+	}
+	file, err := s.fileRepo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return name, data, &util.NotFoundError{Message: "invoice not found"}
+		}
+		return name, data, err
+	}
+
+	data, err = s.storage.Get(ctx, file.BucketName, file.Location)
+	if err != nil {
+		return name, data, err
+	}
+
+	return file.Name, data, nil
+}
+
+func invoiceFileName(paymentID uuid.UUID) string {
+	return fmt.Sprintf("invoice-%s.pdf", paymentID)
+}
+
+// invoiceNumber is the short reference printed on the invoice.
+func invoiceNumber(paymentID uuid.UUID) string {
+	return "INV-" + strings.ToUpper(paymentID.String()[:8])
+}
+
+func renderInvoice(paymentID uuid.UUID, date time.Time, snapshot *entity.PaymentSnapshot) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(invoiceNumber(paymentID), true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "INVOICE", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "Invoice number: "+invoiceNumber(paymentID), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Date: "+date.Format("02 January 2006"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr("Billed to: "+snapshot.FullName), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	widths := []float64{80, 15, 30, 30, 35}
+	pdf.SetFont("Helvetica", "B", 10)
+	for i, header := range []string{"Product", "Qty", "Unit price", "Discount", "Amount"} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 8, header, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range snapshot.Items {
+		quantity := int64(item.Quantity)
+		pdf.CellFormat(widths[0], 7, tr(item.Name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 7, fmt.Sprint(item.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 7, item.Price.String(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 7, item.DiscountAmount.MulInt(quantity).String(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, item.FinalPrice.MulInt(quantity).String(), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	summary := func(label string, amount util.Money, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3], 7, tr(label), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 7, amount.String(), "", 1, "R", false, 0, "")
+	}
+
+	summary("Subtotal", snapshot.SubTotal, false)
+	summary("Discount", snapshot.TotalDiscount, false)
+	for _, tax := range snapshot.Taxes {
+		label := fmt.Sprintf("%s %s%%", tax.Name, tax.Rate.String())
+		if tax.IsInclusive {
+			label += " (included)"
+		}
+		summary(label, tax.Amount, false)
+	}
+	if snapshot.Shipping != nil {
+		summary("Shipping "+snapshot.Shipping.Name, snapshot.Shipping.Cost, false)
+	}
+	summary("Total ("+snapshot.Currency+")", snapshot.Total, true)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
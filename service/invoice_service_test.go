@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+	"time"
+)
+
+func TestGenerateInvoice(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fileMock := mocks.NewMockFileRepository(mockCtrl)
+	storage := service.NewLocalFileStorage(t.TempDir())
+
+	payment := entity.Payment{
+		ID: uuid.New(), Amount: util.MoneyFromInt(1110), Currency: "IDR", Status: entity.PaymentStatusPending,
+		CreatedAt: time.Now(),
+		Snapshot: &entity.PaymentSnapshot{
+			FullName: "Rehan",
+			Currency: "IDR",
+			Items: []entity.PaymentItem{
+				{
+					ProductID: uuid.New(), Name: "Kopi Gayo", Quantity: 2, Price: util.MoneyFromInt(500),
+					FinalPrice: util.MoneyFromInt(500),
+				},
+			},
+			SubTotal: util.MoneyFromInt(1000),
+			Taxes:    []entity.PaymentTax{{Name: "PPN", Rate: util.MoneyFromInt(11), Amount: util.MoneyFromInt(110)}},
+			TotalTax: util.MoneyFromInt(110),
+			Total:    util.MoneyFromInt(1110),
+		},
+	}
+
+	var stored entity.File
+	fileMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.File) (entity.File, error) {
+			stored = *data
+			return *data, nil
+		},
+	)
+
+	invoiceSvc := service.NewInvoiceService(fileMock, storage)
+	_, _, err := invoiceSvc.Generate(ctx, payment)
+	assert.NoError(t, err)
+	assert.Equal(t, service.InvoiceBucket, stored.BucketName)
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"bucket_name": service.InvoiceBucket}}, {squirrel.Eq{"name": stored.Name}}},
+	}
+	fileMock.EXPECT().Get(gomock.Any(), &b).Return(stored, nil)
+
+	name, data, err := invoiceSvc.Get(ctx, payment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.Name, name)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+}
+
+func TestInvoiceRenderedAgainFromSnapshot(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fileMock := mocks.NewMockFileRepository(mockCtrl)
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+
+	payment := entity.Payment{
+		ID: uuid.New(), Amount: util.MoneyFromInt(1000), Currency: "IDR", Status: entity.PaymentStatusPaid,
+		CreatedAt: time.Now(),
+		Snapshot: &entity.PaymentSnapshot{
+			FullName: "Rehan",
+			Currency: "IDR",
+			Items: []entity.PaymentItem{
+				{ProductID: uuid.New(), Name: "Kopi Gayo", Quantity: 2, Price: util.MoneyFromInt(500)},
+			},
+			SubTotal: util.MoneyFromInt(1000),
+			Total:    util.MoneyFromInt(1000),
+		},
+	}
+	fileMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.File{}, sql.ErrNoRows)
+	paymentMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(payment, nil)
+	fileMock.EXPECT().Store(gomock.Any(), gomock.Any()).Return(entity.File{}, nil)
+
+	invoiceSvc := service.NewInvoiceService(fileMock, service.NewLocalFileStorage(t.TempDir()))
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, nil, nil, invoiceSvc, service.NewFakePaymentProvider("secret"),
+	)
+	name, data, err := paymentSvc.Invoice(ctx, payment.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "invoice-"+payment.ID.String()+".pdf", name)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF")))
+}
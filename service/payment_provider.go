@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/util"
+
+	"github.com/google/uuid"
+)
+
+// PaymentProvider is the extension point for payment gateways, FakePaymentProvider is the in-repo one used
+// for tests and local runs.
+type PaymentProvider interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (res Charge, err error)
+	// VerifyWebhook checks signature against payload and decodes the event, an invalid signature is
+	// reported as a *util.UnauthorizedError.
+	VerifyWebhook(payload []byte, signature string) (res WebhookEvent, err error)
+	Refund(ctx context.Context, providerRef string, amount util.Money) (res Refund, err error)
+}
+
+type ChargeRequest struct {
+	Reference string
+	Amount    util.Money
+	Currency  string
+}
+
+// Charge is a charge created at the provider, the customer pays it at PaymentURL.
+type Charge struct {
+	ProviderRef string
+	Status      string
+	PaymentURL  string
+}
+
+// WebhookEvent is a status change of a charge notified by the provider. EventID is unique per notification,
+// providers may send the same one more than once.
+type WebhookEvent struct {
+	EventID     string `json:"event_id"`
+	ProviderRef string `json:"charge_id"`
+	Status      string `json:"status"`
+}
+
+type Refund struct {
+	ProviderRef string
+	Amount      util.Money
+}
+
+// FakePaymentProvider accepts every charge and refund without calling anything. Webhooks are signed with
+// an HMAC-SHA256 of the payload under Secret, hex encoded, which Sign computes for local testing.
+type FakePaymentProvider struct {
+	Secret []byte
+}
+
+func NewFakePaymentProvider(secret string) *FakePaymentProvider {
+	return &FakePaymentProvider{Secret: []byte(secret)}
+}
+
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakePaymentProvider) CreateCharge(ctx context.Context, req ChargeRequest) (res Charge, err error) {
+	ref := "ch_" + uuid.NewString()
+	return Charge{
+		ProviderRef: ref,
+		Status:      entity.PaymentStatusPending,
+		PaymentURL:  "https://pay.example.test/" + ref,
+	}, nil
+}
+
+func (p *FakePaymentProvider) VerifyWebhook(payload []byte, signature string) (res WebhookEvent, err error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.sum(payload)) {
+		return res, &util.UnauthorizedError{Message: "invalid webhook signature"}
+	}
+
+	if err := json.Unmarshal(payload, &res); err != nil {
+		return res, &util.BadRequestError{Message: "invalid webhook payload"}
+	}
+
+	return res, nil
+}
+
+func (p *FakePaymentProvider) Refund(ctx context.Context, providerRef string, amount util.Money) (
+	res Refund, err error,
+) {
+	return Refund{ProviderRef: "re_" + uuid.NewString(), Amount: amount}, nil
+}
+
+// Sign returns the signature the provider would send along payload.
+func (p *FakePaymentProvider) Sign(payload []byte) string {
+	return hex.EncodeToString(p.sum(payload))
+}
+
+func (p *FakePaymentProvider) sum(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.Secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,496 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/metrics"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"log/slog"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// PaymentService takes payment of carts through a PaymentProvider. There are no orders of their own yet, an
+// order is the payment of a cart and shares its id.
+type PaymentService struct {
+	ctx         context.Context
+	paymentRepo persistence.PaymentRepository
+	refundRepo  persistence.RefundRepository
+	cartSvc     CartService
+	invoiceSvc  *InvoiceService
+	provider    PaymentProvider
+}
+
+func NewPaymentService(
+	ctx context.Context, paymentRepo persistence.PaymentRepository, refundRepo persistence.RefundRepository,
+	cartSvc CartService, invoiceSvc *InvoiceService, provider PaymentProvider,
+) *PaymentService {
+	return &PaymentService{
+		ctx: ctx, paymentRepo: paymentRepo, refundRepo: refundRepo, cartSvc: cartSvc, invoiceSvc: invoiceSvc,
+		provider: provider,
+	}
+}
+
+// Store creates a pending charge for the total of the cart, along with its invoice. The payment keeps a
+// snapshot of the priced cart, which stays what was paid for whatever happens to the cart afterwards. It is
+// recorded as pending before the charge is created, with its id as the reference of the charge, so that no
+// charge is ever left without a payment, then it gets the id and status of the charge. An invoice that can't
+// be generated doesn't fail the payment, Invoice renders it when it is first asked for.
+func (s *PaymentService) Store(ctx context.Context, req *request.PaymentAddRequest) (
+	res response.PaymentResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	cart, err := s.cartSvc.Find(
+		ctx, &request.CartCriteria{
+			FullName:        req.FullName,
+			Currency:        req.Currency,
+			AddressID:       req.AddressID,
+			ShippingService: req.ShippingService,
+		},
+	)
+	if err != nil {
+		return res, err
+	}
+	if cart == nil || len(cart.Products) == 0 {
+		return res, &util.BadRequestError{Message: "cart is empty"}
+	}
+	if !cart.Total.IsPositive() {
+		return res, &util.BadRequestError{Message: "nothing to pay"}
+	}
+
+	snapshot := buildPaymentSnapshot(cart)
+	payment := entity.Payment{
+		CartID:    cart.ID,
+		Provider:  s.provider.Name(),
+		Amount:    cart.Total,
+		Currency:  cart.Currency,
+		Status:    entity.PaymentStatusPending,
+		Snapshot:  &snapshot,
+		CreatedAt: time.Now(),
+	}
+	payment.UpdatedAt = payment.CreatedAt
+
+	payment, err = s.paymentRepo.Store(ctx, &payment)
+	if err != nil {
+		return res, err
+	}
+
+	charge, err := s.provider.CreateCharge(
+		ctx, ChargeRequest{Reference: payment.ID.String(), Amount: payment.Amount, Currency: payment.Currency},
+	)
+	if err != nil {
+		payment.Status = entity.PaymentStatusFailed
+		payment.UpdatedAt = time.Now()
+		if _, err := s.paymentRepo.UpdateStatus(
+			context.WithoutCancel(ctx), &payment, entity.PaymentStatusPending,
+		); err != nil {
+			slog.WarnContext(ctx, "payment left pending", "payment_id", payment.ID, "error", err)
+		}
+		return res, err
+	}
+
+	// the charge exists from here on, it is recorded even if the client goes away
+	ctx = context.WithoutCancel(ctx)
+	payment.ProviderRef = charge.ProviderRef
+	payment.Status = charge.Status
+	payment.UpdatedAt = time.Now()
+	if _, err = s.paymentRepo.Update(ctx, &payment); err != nil {
+		slog.WarnContext(
+			ctx, "charge not recorded on its payment", "payment_id", payment.ID, "provider_ref",
+			charge.ProviderRef, "error", err,
+		)
+		return res, err
+	}
+
+	// failing now would have the retry of the client charge the cart again
+	if _, _, err := s.invoiceSvc.Generate(ctx, payment); err != nil {
+		slog.WarnContext(ctx, "invoice not generated", "payment_id", payment.ID, "error", err)
+	}
+
+	metrics.Checkouts.Inc()
+
+	res = buildPaymentResponse(payment)
+	res.PaymentURL = charge.PaymentURL
+
+	return res, nil
+}
+
+// HandleWebhook applies a status change notified by the provider. Only pending payments move to paid or
+// failed, replays and late notifications of a payment already settled are ignored, so the provider can
+// safely retry.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.HandleWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	if event.Status != entity.PaymentStatusPaid && event.Status != entity.PaymentStatusFailed {
+		return &util.BadRequestError{Message: "unknown payment status"}
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"provider": s.provider.Name()}}, {squirrel.Eq{"provider_ref": event.ProviderRef}},
+		},
+	}
+	payment, err := s.paymentRepo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &util.NotFoundError{Message: "payment not found"}
+		}
+		return err
+	}
+
+	if payment.Status != entity.PaymentStatusPending {
+		slog.InfoContext(
+			ctx, "payment already settled, ignoring event", "payment_id", payment.ID, "status", payment.Status,
+			"event_status", event.Status, "event_id", event.EventID,
+		)
+		return nil
+	}
+
+	payment.Status = event.Status
+	payment.UpdatedAt = time.Now()
+	updated, err := s.paymentRepo.UpdateStatus(ctx, &payment, entity.PaymentStatusPending)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		slog.InfoContext(
+			ctx, "payment settled concurrently, ignoring event", "payment_id", payment.ID, "event_id", event.EventID,
+		)
+	}
+
+	return nil
+}
+
+// Refund gives back part or all of a paid payment through the provider, or what was paid for req.Items. Once
+// nothing is left to refund the payment moves to refunded.
+func (s *PaymentService) Refund(ctx context.Context, paymentID string, req *request.RefundAddRequest) (
+	res response.RefundResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Refund")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(paymentID)
+	if err != nil {
+		return res, &util.NotFoundError{Message: "payment not found"}
+	}
+
+	return s.refund(ctx, id, entity.Refund{Amount: req.Amount, Reason: req.Reason, Items: itemQuantities(req.Items)})
+}
+
+// refund gives back record.Amount of the payment id, or what was paid for record.Items when there are some,
+// or else whatever is left of it. The refund is recorded as pending before the provider is asked for it, the
+// payment row being locked meanwhile so that concurrent refunds can't give back more than was paid, then it
+// is marked succeeded or failed with the answer of the provider.
+func (s *PaymentService) refund(ctx context.Context, id uuid.UUID, record entity.Refund) (
+	res response.RefundResponse, err error,
+) {
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+
+	payment, refundable, err := s.reserveRefund(ctx, tx, id, &record)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return res, err
+	}
+	if err = tx.Commit(); err != nil {
+		return res, err
+	}
+
+	// once recorded, the refund is seen through even if the client goes away
+	ctx = context.WithoutCancel(ctx)
+	refund, err := s.provider.Refund(ctx, payment.ProviderRef, record.Amount)
+	if err != nil {
+		record.Status = entity.RefundStatusFailed
+		if _, err := s.refundRepo.Update(ctx, &record); err != nil {
+			slog.WarnContext(ctx, "refund left pending", "refund_id", record.ID, "error", err)
+		}
+		return res, err
+	}
+
+	record.Status = entity.RefundStatusSucceeded
+	record.ProviderRef = refund.ProviderRef
+	if _, err := s.refundRepo.Update(ctx, &record); err != nil {
+		// the provider refunded it, failing here would have the client refund it again
+		record.Status = entity.RefundStatusPending
+		slog.WarnContext(ctx, "refund left pending", "refund_id", record.ID, "error", err)
+	}
+
+	if refundable.IsZero() {
+		payment.Status = entity.PaymentStatusRefunded
+		payment.UpdatedAt = time.Now()
+		if _, err := s.paymentRepo.UpdateStatus(ctx, &payment, entity.PaymentStatusPaid); err != nil {
+			return res, err
+		}
+	}
+
+	res = response.RefundResponse{
+		ID:            record.ID,
+		PaymentID:     payment.ID,
+		Amount:        record.Amount,
+		Reason:        record.Reason,
+		Status:        record.Status,
+		Items:         itemQuantityResponses(record.Items),
+		PaymentStatus: payment.Status,
+		Refundable:    refundable,
+		CreatedAt:     record.CreatedAt,
+	}
+	if record.ReturnID.Valid {
+		res.ReturnID = &record.ReturnID.UUID
+	}
+
+	return res, nil
+}
+
+// reserveRefund locks the payment id within tx, checks record against the refunds made so far and stores it
+// as pending. It returns what is left to refund of the payment once record is.
+func (s *PaymentService) reserveRefund(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, record *entity.Refund) (
+	payment entity.Payment, refundable util.Money, err error,
+) {
+	payment, err = s.paymentRepo.WithTx(tx).GetForUpdate(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return payment, refundable, &util.NotFoundError{Message: "payment not found"}
+		}
+		return payment, refundable, err
+	}
+
+	if payment.Status != entity.PaymentStatusPaid {
+		return payment, refundable, &util.BadRequestError{Message: "only paid payments can be refunded"}
+	}
+
+	refundTx := s.refundRepo.WithTx(tx)
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"payment_id": payment.ID}}, {squirrel.NotEq{"status": entity.RefundStatusFailed}},
+		},
+	}
+	refunds, err := refundTx.Find(ctx, &builder)
+	if err != nil {
+		return payment, refundable, err
+	}
+
+	refundable = payment.Amount.RoundTo(payment.Currency)
+	refunded := make(map[uuid.UUID]int)
+	for _, r := range refunds {
+		if record.ReturnID.Valid && r.ReturnID == record.ReturnID {
+			return payment, refundable, &util.ConflictError{Message: "the return was already refunded"}
+		}
+		refundable = refundable.Sub(r.Amount)
+		for _, item := range r.Items {
+			refunded[item.ProductID] += item.Quantity
+		}
+	}
+
+	amount := record.Amount
+	if len(record.Items) > 0 {
+		if !amount.IsZero() {
+			return payment, refundable, util.NewValidationError(
+				[]util.FieldError{{Field: "amount", Message: "must be empty when items are refunded"}},
+			)
+		}
+		if payment.Snapshot == nil {
+			return payment, refundable, &util.BadRequestError{Message: "the items of the payment are unknown"}
+		}
+		if err := util.NewValidationError(validateItems(payment.Snapshot, record.Items, refunded)); err != nil {
+			return payment, refundable, err
+		}
+		// a part of the items may already have been given back by refunds of an amount
+		amount = itemsAmount(payment.Snapshot, record.Items).Min(refundable)
+	}
+	if amount.IsZero() {
+		amount = refundable
+	}
+	if !amount.IsPositive() || amount.GreaterThan(refundable) || !amount.Equal(amount.RoundTo(payment.Currency)) {
+		return payment, refundable, util.NewValidationError(
+			[]util.FieldError{{Field: "amount", Message: "must be greater than 0 and at most " + refundable.String()}},
+		)
+	}
+
+	record.PaymentID = payment.ID
+	record.Amount = amount.RoundTo(payment.Currency)
+	record.Status = entity.RefundStatusPending
+	record.CreatedAt = time.Now()
+	if _, err = refundTx.Store(ctx, record); err != nil {
+		return payment, refundable, err
+	}
+
+	return payment, refundable.Sub(record.Amount), nil
+}
+
+// Invoice returns the PDF invoice of the order orderID. An invoice that wasn't stored, or was lost, is
+// rendered again from the snapshot of the payment.
+func (s *PaymentService) Invoice(ctx context.Context, orderID string) (name string, data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentService.Invoice")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(orderID)
+	if err != nil {
+		return name, data, &util.NotFoundError{Message: "order not found"}
+	}
+
+	name, data, err = s.invoiceSvc.Get(ctx, id)
+	var notFound *util.NotFoundError
+	if !errors.As(err, &notFound) {
+		return name, data, err
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": id}}}}
+	payment, err := s.paymentRepo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return name, data, &util.NotFoundError{Message: "order not found"}
+		}
+		return name, data, err
+	}
+
+	return s.invoiceSvc.Generate(ctx, payment)
+}
+
+// validateItems checks that items are part of snapshot, each in a quantity at most the one bought less the
+// one already taken by product, by refunds or returns. The quantities of items are added to taken.
+func validateItems(snapshot *entity.PaymentSnapshot, items entity.ItemQuantities, taken map[uuid.UUID]int) (
+	fields []util.FieldError,
+) {
+	if len(items) == 0 {
+		return []util.FieldError{{Field: "items", Message: "must not be empty"}}
+	}
+
+	for i, item := range items {
+		line, ok := snapshot.Item(item.ProductID)
+		if !ok {
+			fields = append(
+				fields, util.FieldError{Field: fmt.Sprintf("items[%d].product_id", i), Message: "isn't part of the payment"},
+			)
+			continue
+		}
+
+		left := line.Quantity - taken[item.ProductID]
+		if item.Quantity <= 0 || item.Quantity > left {
+			fields = append(
+				fields, util.FieldError{
+					Field: fmt.Sprintf("items[%d].quantity", i), Message: fmt.Sprintf("must be between 1 and %d", left),
+				},
+			)
+			continue
+		}
+		taken[item.ProductID] += item.Quantity
+	}
+
+	return fields
+}
+
+// itemsAmount returns what was paid for items of snapshot: their price after discount, along with their share
+// of the taxes added on top of it. Shipping isn't given back.
+func itemsAmount(snapshot *entity.PaymentSnapshot, items entity.ItemQuantities) util.Money {
+	amount := util.Money{}
+	for _, item := range items {
+		line, _ := snapshot.Item(item.ProductID)
+		amount = amount.Add(line.FinalPrice.MulInt(int64(item.Quantity)))
+	}
+
+	exclusive := util.Money{}
+	for _, tax := range snapshot.Taxes {
+		if !tax.IsInclusive {
+			exclusive = exclusive.Add(tax.Amount)
+		}
+	}
+	net := snapshot.SubTotal.Sub(snapshot.TotalDiscount)
+	if exclusive.IsPositive() && net.IsPositive() {
+		amount = amount.Add(exclusive.Ratio(amount, net))
+	}
+
+	return amount.RoundTo(snapshot.Currency)
+}
+
+func itemQuantities(items []request.ItemQuantityRequest) entity.ItemQuantities {
+	res := make(entity.ItemQuantities, 0, len(items))
+	for _, item := range items {
+		res = append(res, entity.ItemQuantity{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	return res
+}
+
+func itemQuantityResponses(items entity.ItemQuantities) []response.ItemQuantityResponse {
+	res := make([]response.ItemQuantityResponse, 0, len(items))
+	for _, item := range items {
+		res = append(res, response.ItemQuantityResponse{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	return res
+}
+
+func buildPaymentSnapshot(cart *response.CartResponse) entity.PaymentSnapshot {
+	res := entity.PaymentSnapshot{
+		FullName:      cart.FullName,
+		Currency:      cart.Currency,
+		Items:         make([]entity.PaymentItem, 0, len(cart.Products)),
+		SubTotal:      cart.SubTotal,
+		TotalDiscount: cart.TotalDiscount,
+		Taxes:         make([]entity.PaymentTax, 0, len(cart.Taxes)),
+		TotalTax:      cart.TotalTax,
+		Total:         cart.Total,
+	}
+
+	for _, p := range cart.Products {
+		res.Items = append(
+			res.Items, entity.PaymentItem{
+				ProductID:      p.Product.ID,
+				Name:           p.Product.Name,
+				Quantity:       p.Quantity,
+				Price:          p.Product.Price,
+				DiscountAmount: p.Product.DiscountAmount,
+				FinalPrice:     p.Product.FinalPrice,
+			},
+		)
+	}
+	for _, tax := range cart.Taxes {
+		res.Taxes = append(
+			res.Taxes, entity.PaymentTax{Name: tax.Name, Rate: tax.Rate, IsInclusive: tax.IsInclusive, Amount: tax.Amount},
+		)
+	}
+	if cart.Shipping != nil {
+		res.Shipping = &entity.PaymentShipping{
+			Service: cart.Shipping.Service, Name: cart.Shipping.Name, Cost: cart.Shipping.Cost,
+		}
+	}
+
+	return res
+}
+
+func buildPaymentResponse(payment entity.Payment) response.PaymentResponse {
+	return response.PaymentResponse{
+		ID:        payment.ID,
+		CartID:    payment.CartID,
+		Provider:  payment.Provider,
+		Amount:    payment.Amount.RoundTo(payment.Currency),
+		Currency:  payment.Currency,
+		Status:    payment.Status,
+		CreatedAt: payment.CreatedAt,
+	}
+}
@@ -0,0 +1,534 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestHandleWebhookPaid(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	provider := service.NewFakePaymentProvider("secret")
+
+	payment := entity.Payment{
+		ID: uuid.New(), Provider: "fake", ProviderRef: "ch_1", Amount: util.MoneyFromInt(1000),
+		Status: entity.PaymentStatusPending,
+	}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"provider": "fake"}}, {squirrel.Eq{"provider_ref": "ch_1"}}},
+	}
+	paymentMock.EXPECT().Get(gomock.Any(), &b).Return(payment, nil)
+	paymentMock.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), entity.PaymentStatusPending).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment, from string) (bool, error) {
+			assert.Equal(t, entity.PaymentStatusPaid, data.Status)
+			return true, nil
+		},
+	)
+
+	payload := []byte(`{"event_id":"evt_1","charge_id":"ch_1","status":"paid"}`)
+	paymentSvc := service.NewPaymentService(ctx, paymentMock, nil, nil, nil, provider)
+	err := paymentSvc.HandleWebhook(ctx, payload, provider.Sign(payload))
+	assert.NoError(t, err)
+}
+
+func TestHandleWebhookReplay(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	provider := service.NewFakePaymentProvider("secret")
+
+	payment := entity.Payment{ID: uuid.New(), Provider: "fake", ProviderRef: "ch_1", Status: entity.PaymentStatusPaid}
+	paymentMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(payment, nil)
+
+	payload := []byte(`{"event_id":"evt_1","charge_id":"ch_1","status":"paid"}`)
+	paymentSvc := service.NewPaymentService(ctx, paymentMock, nil, nil, nil, provider)
+	err := paymentSvc.HandleWebhook(ctx, payload, provider.Sign(payload))
+	assert.NoError(t, err)
+}
+
+func TestHandleWebhookInvalidSignature(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	payload := []byte(`{"event_id":"evt_1","charge_id":"ch_1","status":"paid"}`)
+
+	paymentSvc := service.NewPaymentService(
+		context.TODO(), paymentMock, nil, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	err := paymentSvc.HandleWebhook(context.TODO(), payload, service.NewFakePaymentProvider("other").Sign(payload))
+	assert.IsType(t, &util.UnauthorizedError{}, err)
+}
+
+func TestHandleWebhookError(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	provider := service.NewFakePaymentProvider("secret")
+	paymentMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.Payment{}, errors.New("something wrong"))
+
+	payload := []byte(`{"event_id":"evt_1","charge_id":"ch_1","status":"failed"}`)
+	paymentSvc := service.NewPaymentService(ctx, paymentMock, nil, nil, nil, provider)
+	err := paymentSvc.HandleWebhook(ctx, payload, provider.Sign(payload))
+	assert.Error(t, err)
+}
+
+func TestRefundPartial(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	payment := entity.Payment{
+		ID: uuid.New(), Provider: "fake", ProviderRef: "ch_1", Amount: util.MoneyFromInt(1000),
+		Currency: "IDR", Status: entity.PaymentStatusPaid,
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"payment_id": payment.ID}}, {squirrel.NotEq{"status": entity.RefundStatusFailed}},
+		},
+	}
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), &b).Return([]entity.Refund{{Amount: util.MoneyFromInt(300)}}, nil)
+	gomock.InOrder(
+		refundMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+				assert.Equal(t, entity.RefundStatusPending, data.Status)
+				assert.Empty(t, data.ProviderRef)
+				return *data, nil
+			},
+		),
+		refundMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+				assert.Equal(t, entity.RefundStatusSucceeded, data.Status)
+				assert.NotEmpty(t, data.ProviderRef)
+				return *data, nil
+			},
+		),
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	req := request.RefundAddRequest{Amount: util.MoneyFromInt(200), Reason: "damaged"}
+	res, err := paymentSvc.Refund(ctx, payment.ID.String(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "200.00", res.Amount.String())
+	assert.Equal(t, "500.00", res.Refundable.String())
+	assert.Equal(t, entity.RefundStatusSucceeded, res.Status)
+	assert.Equal(t, entity.PaymentStatusPaid, res.PaymentStatus)
+}
+
+func TestRefundRemaining(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	payment := entity.Payment{
+		ID: uuid.New(), Provider: "fake", ProviderRef: "ch_1", Amount: util.MoneyFromInt(1000),
+		Currency: "IDR", Status: entity.PaymentStatusPaid,
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.Refund{{Amount: util.MoneyFromInt(300)}}, nil)
+	refundMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	refundMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	paymentMock.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), entity.PaymentStatusPaid).Return(true, nil)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	res, err := paymentSvc.Refund(ctx, payment.ID.String(), &request.RefundAddRequest{Reason: "cancelled"})
+	assert.NoError(t, err)
+	assert.Equal(t, "700.00", res.Amount.String())
+	assert.True(t, res.Refundable.IsZero())
+	assert.Equal(t, entity.PaymentStatusRefunded, res.PaymentStatus)
+}
+
+// failingRefunds is a provider whose refunds fail.
+type failingRefunds struct {
+	service.PaymentProvider
+}
+
+func (failingRefunds) Refund(ctx context.Context, providerRef string, amount util.Money) (
+	service.Refund, error,
+) {
+	return service.Refund{}, errors.New("provider unavailable")
+}
+
+func TestRefundProviderFailure(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	payment := entity.Payment{
+		ID: uuid.New(), ProviderRef: "ch_1", Amount: util.MoneyFromInt(1000), Currency: "IDR",
+		Status: entity.PaymentStatusPaid,
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.Refund{}, nil)
+	refundMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	refundMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			assert.Equal(t, entity.RefundStatusFailed, data.Status)
+			return *data, nil
+		},
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, failingRefunds{service.NewFakePaymentProvider("secret")},
+	)
+	_, err := paymentSvc.Refund(ctx, payment.ID.String(), &request.RefundAddRequest{Reason: "cancelled"})
+	assert.EqualError(t, err, "provider unavailable")
+}
+
+func TestRefundItems(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	coffee, tea := uuid.New(), uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), ProviderRef: "ch_1", Amount: util.MoneyFromInt(1200), Currency: "IDR",
+		Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{
+			Currency: "IDR",
+			Items: []entity.PaymentItem{
+				{ProductID: coffee, Quantity: 2, FinalPrice: util.MoneyFromInt(300)},
+				{ProductID: tea, Quantity: 1, FinalPrice: util.MoneyFromInt(400)},
+			},
+			SubTotal: util.MoneyFromInt(1000),
+			Taxes:    []entity.PaymentTax{{Name: "VAT", Amount: util.MoneyFromInt(100)}},
+			TotalTax: util.MoneyFromInt(100),
+			Shipping: &entity.PaymentShipping{Cost: util.MoneyFromInt(100)},
+			Total:    util.MoneyFromInt(1200),
+		},
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.Refund{
+			{Amount: util.MoneyFromInt(330), Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 1}}},
+		}, nil,
+	)
+	refundMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	refundMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+
+	// the coffee left and the tea, with their tenth of VAT, shipping isn't refunded
+	req := request.RefundAddRequest{
+		Reason: "damaged",
+		Items:  []request.ItemQuantityRequest{{ProductID: coffee, Quantity: 1}, {ProductID: tea, Quantity: 1}},
+	}
+	res, err := paymentSvc.Refund(ctx, payment.ID.String(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "770.00", res.Amount.String())
+	assert.Equal(t, "100.00", res.Refundable.String())
+	assert.Len(t, res.Items, 2)
+}
+
+func TestRefundItemsAlreadyRefunded(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	coffee := uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), Amount: util.MoneyFromInt(600), Currency: "IDR", Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{
+			Currency: "IDR",
+			Items:    []entity.PaymentItem{{ProductID: coffee, Quantity: 2, FinalPrice: util.MoneyFromInt(300)}},
+		},
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.Refund{
+			{Amount: util.MoneyFromInt(300), Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 1}}},
+		}, nil,
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	req := request.RefundAddRequest{
+		Reason: "damaged",
+		Items:  []request.ItemQuantityRequest{{ProductID: coffee, Quantity: 2}, {ProductID: uuid.New(), Quantity: 1}},
+	}
+	_, err := paymentSvc.Refund(ctx, payment.ID.String(), &req)
+	var badRequest *util.BadRequestError
+	if assert.ErrorAs(t, err, &badRequest) {
+		assert.Equal(
+			t, []util.FieldError{
+				{Field: "items[0].quantity", Message: "must be between 1 and 1"},
+				{Field: "items[1].product_id", Message: "isn't part of the payment"},
+			}, badRequest.Fields,
+		)
+	}
+}
+
+func TestRefundTooMuch(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+
+	payment := entity.Payment{ID: uuid.New(), Amount: util.MoneyFromInt(1000), Status: entity.PaymentStatusPaid}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.Refund{}, nil)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	req := request.RefundAddRequest{Amount: util.MoneyFromInt(1001), Reason: "damaged"}
+	_, err := paymentSvc.Refund(ctx, payment.ID.String(), &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestRefundNotPaid(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	payment := entity.Payment{ID: uuid.New(), Amount: util.MoneyFromInt(1000), Status: entity.PaymentStatusPending}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+
+	paymentSvc := service.NewPaymentService(ctx, paymentMock, nil, nil, nil, service.NewFakePaymentProvider("secret"))
+	_, err := paymentSvc.Refund(ctx, payment.ID.String(), &request.RefundAddRequest{Reason: "damaged"})
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+// cartFinder prices a single cart, the rest of service.CartService isn't used by payments.
+type cartFinder struct {
+	service.CartService
+	cart response.CartResponse
+}
+
+func (f cartFinder) Find(ctx context.Context, req *request.CartCriteria) (*response.CartResponse, error) {
+	cart := f.cart
+	return &cart, nil
+}
+
+func TestStorePaymentSnapshotsCart(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	fileMock := mocks.NewMockFileRepository(mockCtrl)
+
+	productID := uuid.New()
+	cart := response.CartResponse{
+		ID:       uuid.New(),
+		FullName: "Rehan",
+		Currency: "IDR",
+		Products: []response.CartResponseProduct{
+			{
+				Product: response.ProductResponse{
+					ID: productID, Name: "Kopi Gayo", Price: util.MoneyFromInt(500),
+					DiscountAmount: util.MoneyFromInt(50), FinalPrice: util.MoneyFromInt(450),
+				},
+				Quantity: 2,
+			},
+		},
+		SubTotal:      util.MoneyFromInt(1000),
+		TotalDiscount: util.MoneyFromInt(100),
+		Shipping:      &response.ShippingOptionResponse{Service: "regular", Name: "Regular", Cost: util.MoneyFromInt(100)},
+		Total:         util.MoneyFromInt(1000),
+	}
+
+	paymentMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment) (entity.Payment, error) {
+			assert.Equal(t, "1000.00", data.Amount.String())
+			if assert.NotNil(t, data.Snapshot) {
+				assert.Equal(t, "Rehan", data.Snapshot.FullName)
+				item, ok := data.Snapshot.Item(productID)
+				assert.True(t, ok)
+				assert.Equal(t, 2, item.Quantity)
+				assert.Equal(t, "450.00", item.FinalPrice.String())
+				assert.Equal(t, "100.00", data.Snapshot.Shipping.Cost.String())
+			}
+			assert.Equal(t, entity.PaymentStatusPending, data.Status)
+			assert.Empty(t, data.ProviderRef)
+			data.GenerateUUID()
+			return *data, nil
+		},
+	)
+	paymentMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment) (entity.Payment, error) {
+			assert.NotEmpty(t, data.ProviderRef)
+			return *data, nil
+		},
+	)
+	fileMock.EXPECT().Store(gomock.Any(), gomock.Any()).Return(entity.File{}, nil)
+
+	invoiceSvc := service.NewInvoiceService(fileMock, service.NewLocalFileStorage(t.TempDir()))
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, nil, cartFinder{cart: cart}, invoiceSvc, service.NewFakePaymentProvider("secret"),
+	)
+	res, err := paymentSvc.Store(ctx, &request.PaymentAddRequest{FullName: "Rehan"})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.PaymentStatusPending, res.Status)
+	assert.NotEmpty(t, res.PaymentURL)
+}
+
+// failingCharges is a provider whose charges fail.
+type failingCharges struct {
+	service.PaymentProvider
+}
+
+func (failingCharges) CreateCharge(ctx context.Context, req service.ChargeRequest) (service.Charge, error) {
+	return service.Charge{}, errors.New("provider unavailable")
+}
+
+func TestStorePaymentChargeFailure(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	cart := response.CartResponse{
+		ID:       uuid.New(),
+		FullName: "Rehan",
+		Currency: "IDR",
+		Products: []response.CartResponseProduct{
+			{Product: response.ProductResponse{ID: uuid.New(), FinalPrice: util.MoneyFromInt(500)}, Quantity: 1},
+		},
+		Total: util.MoneyFromInt(500),
+	}
+
+	var paymentID uuid.UUID
+	paymentMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment) (entity.Payment, error) {
+			data.GenerateUUID()
+			paymentID = data.ID
+			return *data, nil
+		},
+	)
+	// the payment recorded before the charge doesn't stay pending
+	paymentMock.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), entity.PaymentStatusPending).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment, from string) (bool, error) {
+			assert.Equal(t, paymentID, data.ID)
+			assert.Equal(t, entity.PaymentStatusFailed, data.Status)
+			return true, nil
+		},
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, nil, cartFinder{cart: cart}, nil, failingCharges{service.NewFakePaymentProvider("secret")},
+	)
+	_, err := paymentSvc.Store(ctx, &request.PaymentAddRequest{FullName: "Rehan"})
+	assert.EqualError(t, err, "provider unavailable")
+}
+
+func TestStorePaymentInvoiceFailure(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	fileMock := mocks.NewMockFileRepository(mockCtrl)
+	cart := response.CartResponse{
+		ID:       uuid.New(),
+		FullName: "Rehan",
+		Currency: "IDR",
+		Products: []response.CartResponseProduct{
+			{Product: response.ProductResponse{ID: uuid.New(), FinalPrice: util.MoneyFromInt(500)}, Quantity: 1},
+		},
+		Total: util.MoneyFromInt(500),
+	}
+
+	paymentMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment) (entity.Payment, error) {
+			data.GenerateUUID()
+			return *data, nil
+		},
+	)
+	paymentMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Payment) (entity.Payment, error) {
+			return *data, nil
+		},
+	)
+	fileMock.EXPECT().Store(gomock.Any(), gomock.Any()).Return(entity.File{}, errors.New("connection refused"))
+
+	invoiceSvc := service.NewInvoiceService(fileMock, service.NewLocalFileStorage(t.TempDir()))
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, nil, cartFinder{cart: cart}, invoiceSvc, service.NewFakePaymentProvider("secret"),
+	)
+	res, err := paymentSvc.Store(ctx, &request.PaymentAddRequest{FullName: "Rehan"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.PaymentURL)
+}
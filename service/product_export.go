@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// exportBatchSize is the number of products read per query while exporting.
+const exportBatchSize = 500
+
+var productExportColumns = []string{
+	"id", "name", "price", "currency", "description", "category", "weight", "is_discount", "discount_type",
+	"discount_value", "start_date_discount", "end_date_discount", "discount_amount", "final_price", "stock",
+}
+
+// productExporter writes exported products in one format.
+type productExporter interface {
+	Write(p response.ProductResponse) error
+	Flush() error
+	Close() error
+}
+
+// ExportContentType returns the Content-Type of format, or false when format can't be exported.
+func ExportContentType(format string) (string, bool) {
+	switch format {
+	case request.ImportFormatCSV:
+		return "text/csv", true
+	case request.ImportFormatJSONL:
+		return "application/x-ndjson", true
+	case request.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", true
+	}
+
+	return "", false
+}
+
+// Export writes every product matching req to w, priced like Find. Products are read by batches of
+// exportBatchSize ordered by id, each batch being written and flushed before the next one is read, so the
+// catalogue is never held in memory as a whole.
+func (s *ProductService) Export(ctx context.Context, req *request.ProductExportRequest, w io.Writer) (err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Export")
+	defer func() { tracing.End(span, err) }()
+
+	if _, ok := ExportContentType(req.Format); !ok {
+		return util.NewValidationError([]util.FieldError{{Field: "format", Message: "must be csv, jsonl or xlsx"}})
+	}
+
+	var conv *CurrencyConverter
+	if req.Currency != "" {
+		conv, err = s.currencySvc.Converter(req.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	exporter, err := newProductExporter(req.Format, w)
+	if err != nil {
+		return err
+	}
+
+	limit := uint64(exportBatchSize)
+	last := uuid.Nil
+	for {
+		builder := persistence.QueryBuilderCriteria{}
+		builder.Where = productFilter(&req.ProductCriteria)
+		builder.Where.And = append(builder.Where.And, squirrel.And{squirrel.Gt{"id": last}})
+		builder.Order = map[string]string{"id": "ASC"}
+		builder.Limit = &limit
+
+		results, err := s.productRepo.Find(ctx, &builder)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			break
+		}
+
+		discounts, err := s.discountSvc.BestDiscounts(ctx, results, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for _, val := range results {
+			data := buildProductResponse(val, discounts[val.ID])
+			if conv != nil {
+				if err := convertProductResponse(ctx, &data, conv); err != nil {
+					return err
+				}
+			}
+
+			if err := exporter.Write(data); err != nil {
+				return err
+			}
+		}
+
+		if err := exporter.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		if len(results) < exportBatchSize {
+			break
+		}
+		last = results[len(results)-1].ID
+	}
+
+	return exporter.Close()
+}
+
+func newProductExporter(format string, w io.Writer) (productExporter, error) {
+	switch format {
+	case request.ImportFormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvProductExporter{w: cw}, cw.Write(productExportColumns)
+	case request.ImportFormatJSONL:
+		return &jsonlProductExporter{enc: json.NewEncoder(w)}, nil
+	default:
+		xw, err := util.NewXLSXWriter(w, "Products")
+		if err != nil {
+			return nil, err
+		}
+
+		header := make([]interface{}, 0, len(productExportColumns))
+		for _, column := range productExportColumns {
+			header = append(header, column)
+		}
+		return &xlsxProductExporter{w: xw}, xw.WriteRow(header...)
+	}
+}
+
+type csvProductExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvProductExporter) Write(p response.ProductResponse) error {
+	record := []string{
+		p.ID.String(), p.Name, p.Price.String(), p.Currency, p.Description, p.Category, strconv.Itoa(p.Weight),
+		strconv.FormatBool(p.IsDiscount), p.DiscountType, p.DiscountValue.String(),
+		exportDate(p.StartDateDiscount), exportDate(p.EndDateDiscount), p.DiscountAmount.String(),
+		p.FinalPrice.String(), strconv.Itoa(p.Stock),
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvProductExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvProductExporter) Close() error {
+	return e.Flush()
+}
+
+type jsonlProductExporter struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlProductExporter) Write(p response.ProductResponse) error {
+	return e.enc.Encode(p)
+}
+
+func (e *jsonlProductExporter) Flush() error {
+	return nil
+}
+
+func (e *jsonlProductExporter) Close() error {
+	return nil
+}
+
+type xlsxProductExporter struct {
+	w *util.XLSXWriter
+}
+
+func (e *xlsxProductExporter) Write(p response.ProductResponse) error {
+	return e.w.WriteRow(
+		p.ID.String(), p.Name, p.Price, p.Currency, p.Description, p.Category, p.Weight, p.IsDiscount,
+		p.DiscountType, p.DiscountValue, exportDate(p.StartDateDiscount), exportDate(p.EndDateDiscount),
+		p.DiscountAmount, p.FinalPrice, p.Stock,
+	)
+}
+
+func (e *xlsxProductExporter) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *xlsxProductExporter) Close() error {
+	return e.w.Close()
+}
+
+func exportDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+
+	return t.Format(dateLayout)
+}
@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"strings"
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+
+	products := []entity.Product{
+		{ID: uuid.New(), Name: "Kopi, Gayo", Price: util.MoneyFromInt(25000), Currency: "IDR", Weight: 250},
+		{ID: uuid.New(), Name: "Teh", Price: util.MustMoney("12000.5"), Currency: "IDR"},
+	}
+
+	limit := uint64(500)
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.ILike{"name": "%e%"}}, {squirrel.Gt{"id": uuid.Nil}}},
+	}
+	b.Order = map[string]string{"id": "ASC"}
+	b.Limit = &limit
+	productMock.EXPECT().Find(gomock.Any(), &b).Return(products, nil)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	productSvc := service.NewProductService(ctx, productMock, discountSvc, nil)
+
+	var buf bytes.Buffer
+	req := request.ProductExportRequest{Format: request.ImportFormatCSV}
+	req.Search = "e"
+	err := productSvc.Export(ctx, &req, &buf)
+	assert.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, "Kopi, Gayo", records[1][1])
+	assert.Equal(t, "250", records[1][6])
+	assert.Equal(t, "12000.50", records[2][2])
+	assert.Equal(t, "12000.50", records[2][13])
+}
+
+func TestExportJSONL(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
+
+	productMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.Product{{ID: uuid.New(), Name: "Teh", Price: util.MoneyFromInt(12000), Currency: "IDR"}}, nil,
+	)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	productSvc := service.NewProductService(ctx, productMock, discountSvc, nil)
+
+	var buf bytes.Buffer
+	err := productSvc.Export(ctx, &request.ProductExportRequest{Format: request.ImportFormatJSONL}, &buf)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"name":"Teh"`)
+	assert.Contains(t, lines[0], `"final_price":"12000.00"`)
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	productSvc := service.NewProductService(context.TODO(), nil, nil, nil)
+
+	var buf bytes.Buffer
+	err := productSvc.Export(context.TODO(), &request.ProductExportRequest{Format: "pdf"}, &buf)
+	assert.IsType(t, &util.BadRequestError{}, err)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// importBatchSize is the number of rows looked up per query and written per transaction.
+const importBatchSize = 100
+
+type productImportRow struct {
+	Row     int
+	Request request.ProductAddRequest
+	Product entity.Product
+}
+
+// Import validates every row of a CSV or JSON Lines catalogue before writing anything. Rows are checked
+// the same way as Store, names must be unique within the file, and names already in the catalogue are
+// rejected unless req.Upsert is set, in which case the existing product is updated.
+//
+// Nothing is written when any row fails or with req.DryRun. Otherwise rows are written in transactions of
+// importBatchSize rows, existing names being looked up once per batch instead of once per row. A batch
+// failing to write leaves the batches before it committed, res.Created and res.Updated then counting only
+// them, the first rows of the file. A row clashing with a concurrent write, such as its name being taken in
+// between, is reported in res.Errors along with the ConflictError returned, so that the import can be
+// resumed from it.
+func (s *ProductService) Import(ctx context.Context, req *request.ProductImportRequest, body io.Reader) (
+	res response.ProductImportResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "ProductService.Import")
+	defer func() { tracing.End(span, err) }()
+
+	res = response.ProductImportResponse{DryRun: req.DryRun, Errors: make([]response.ProductImportRowError, 0)}
+
+	var rows []productImportRow
+	switch req.Format {
+	case request.ImportFormatCSV:
+		rows, res.Errors, err = parseProductCSV(body)
+	case request.ImportFormatJSONL:
+		rows, res.Errors, err = parseProductJSONL(body)
+	default:
+		return res, util.NewValidationError([]util.FieldError{{Field: "format", Message: "must be csv or jsonl"}})
+	}
+	if err != nil {
+		return res, err
+	}
+	res.Total = len(rows) + len(res.Errors)
+
+	seen := make(map[string]int)
+	valid := make([]productImportRow, 0, len(rows))
+	for _, row := range rows {
+		if first, ok := seen[row.Request.Name]; ok {
+			res.Errors = append(
+				res.Errors, response.ProductImportRowError{
+					Row: row.Row, Name: row.Request.Name, Message: fmt.Sprintf("duplicate of row %d", first),
+				},
+			)
+			continue
+		}
+		seen[row.Request.Name] = row.Row
+
+		row.Product, err = s.validateImportRow(&row.Request)
+		if err != nil {
+			res.Errors = append(res.Errors, importRowError(row, err))
+			continue
+		}
+
+		valid = append(valid, row)
+	}
+
+	existing, err := s.findByNames(ctx, valid)
+	if err != nil {
+		return res, err
+	}
+
+	for i, row := range valid {
+		p, ok := existing[row.Request.Name]
+		if !ok {
+			res.Created++
+			continue
+		}
+
+		if !req.Upsert {
+			res.Errors = append(
+				res.Errors, response.ProductImportRowError{
+					Row: row.Row, Name: row.Request.Name, Message: "product already exists",
+				},
+			)
+			continue
+		}
+
+		// the update fails with a ConflictError if the product changes before the batch is written
+		valid[i].Product.ID, valid[i].Product.Version = p.ID, p.Version
+		if valid[i].Request.Stock == nil {
+			valid[i].Product.Stock = p.Stock
+		}
+		res.Updated++
+	}
+
+	res.Failed = len(res.Errors)
+	if res.Failed > 0 {
+		res.Created, res.Updated = 0, 0
+		return res, nil
+	}
+	if req.DryRun {
+		return res, nil
+	}
+
+	// from here on they count the rows written
+	res.Created, res.Updated = 0, 0
+	for start := 0; start < len(valid); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(valid) {
+			end = len(valid)
+		}
+
+		if err := s.importBatch(ctx, valid[start:end], &res); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// validateImportRow applies the checks the handler binding does for Store, then the ones of Store itself.
+func (s *ProductService) validateImportRow(req *request.ProductAddRequest) (res entity.Product, err error) {
+	fields := make([]util.FieldError, 0)
+	if req.Name == "" {
+		fields = append(fields, util.FieldError{Field: "name", Message: "is required"})
+	} else if len([]rune(req.Name)) > 50 {
+		fields = append(fields, util.FieldError{Field: "name", Message: "must be at most 50 characters"})
+	}
+	if req.Description == "" {
+		fields = append(fields, util.FieldError{Field: "description", Message: "is required"})
+	}
+
+	res, err = s.validateStore(req)
+	if e, ok := err.(*util.BadRequestError); ok {
+		fields = append(fields, e.Fields...)
+	}
+
+	return res, util.NewValidationError(fields)
+}
+
+// findByNames returns the products named like rows, keyed by name.
+func (s *ProductService) findByNames(ctx context.Context, rows []productImportRow) (
+	res map[string]entity.Product, err error,
+) {
+	res = make(map[string]entity.Product)
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		names := make([]string, 0, end-start)
+		for _, row := range rows[start:end] {
+			names = append(names, row.Request.Name)
+		}
+
+		builder := persistence.QueryBuilderCriteria{}
+		builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": names}}}}
+		products, err := s.productRepo.Find(ctx, &builder)
+		if err != nil {
+			return res, err
+		}
+
+		for _, p := range products {
+			res[p.Name] = p
+		}
+	}
+
+	return res, nil
+}
+
+// importBatch writes rows in a transaction and adds them to the counts of res once committed. A row failing
+// with a ConflictError is added to the errors of res.
+func (s *ProductService) importBatch(
+	ctx context.Context, rows []productImportRow, res *response.ProductImportResponse,
+) (err error) {
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	productTx := s.productRepo.WithTx(tx)
+	defer productTx.EndTx(ctx)
+	var created, updated int
+	for i := range rows {
+		if rows[i].Product.ID != uuid.Nil {
+			_, err = productTx.Update(ctx, &rows[i].Product)
+			updated++
+		} else {
+			_, err = productTx.Store(ctx, &rows[i].Product)
+			created++
+		}
+		if err != nil {
+			if err := tx.Rollback(); err != nil {
+				slog.WarnContext(ctx, "rollback failed", "error", err)
+			}
+			var conflict *util.ConflictError
+			if errors.As(err, &conflict) {
+				res.Errors = append(res.Errors, importRowError(rows[i], err))
+				res.Failed = len(res.Errors)
+			}
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	res.Created += created
+	res.Updated += updated
+
+	return nil
+}
+
+// importReadError reports an error reading the file: a BadRequestError prefixed by message when the file is
+// malformed or too large, err itself when the body couldn't be read, e.g. as the client went away.
+func importReadError(message string, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) || errors.Is(err, bufio.ErrTooLong) {
+		return &util.BadRequestError{Message: message + ": " + err.Error()}
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &util.BadRequestError{Message: fmt.Sprintf("file is larger than %d bytes", tooLarge.Limit)}
+	}
+
+	return err
+}
+
+func importRowError(row productImportRow, err error) response.ProductImportRowError {
+	res := response.ProductImportRowError{Row: row.Row, Name: row.Request.Name, Message: err.Error()}
+	if e, ok := err.(*util.BadRequestError); ok {
+		res.Fields = e.Fields
+	}
+
+	return res
+}
+
+// parseProductCSV reads a CSV whose header names the columns like the JSON fields of
+// request.ProductAddRequest, only name, price and description are required. Row of the results is the
+// line in the file the record starts at, quoted fields spanning several lines.
+func parseProductCSV(body io.Reader) (
+	rows []productImportRow, errs []response.ProductImportRowError, err error,
+) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return rows, errs, &util.BadRequestError{Message: "file is empty"}
+	}
+	if err != nil {
+		return rows, errs, importReadError("invalid csv", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"name", "price", "description"} {
+		if _, ok := columns[name]; !ok {
+			return rows, errs, &util.BadRequestError{Message: fmt.Sprintf("missing column %s", name)}
+		}
+	}
+
+	rows = make([]productImportRow, 0)
+	errs = make([]response.ProductImportRowError, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// the reader goes on after a malformed line but fails again on anything else, such as the body
+		// exceeding its limit
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, response.ProductImportRowError{Row: parseErr.StartLine, Message: err.Error()})
+			continue
+		}
+		if err != nil {
+			return rows, errs, importReadError("invalid csv", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row, fields := productImportRowFromCSV(line, value)
+		if len(fields) > 0 {
+			errs = append(
+				errs, response.ProductImportRowError{
+					Row: line, Name: row.Request.Name, Message: "validation failed", Fields: fields,
+				},
+			)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, errs, nil
+}
+
+func productImportRowFromCSV(line int, value func(column string) string) (
+	row productImportRow, fields []util.FieldError,
+) {
+	row = productImportRow{
+		Row: line,
+		Request: request.ProductAddRequest{
+			Name:              value("name"),
+			Currency:          value("currency"),
+			Description:       value("description"),
+			Category:          value("category"),
+			DiscountType:      value("discount_type"),
+			StartDateDiscount: value("start_date_discount"),
+			EndDateDiscount:   value("end_date_discount"),
+		},
+	}
+
+	var err error
+	if v := value("price"); v != "" {
+		if row.Request.Price, err = util.MoneyFromString(v); err != nil {
+			fields = append(fields, util.FieldError{Field: "price", Message: "must be a number"})
+		}
+	}
+	if v := value("discount_value"); v != "" {
+		if row.Request.DiscountValue, err = util.MoneyFromString(v); err != nil {
+			fields = append(fields, util.FieldError{Field: "discount_value", Message: "must be a number"})
+		}
+	}
+	if v := value("weight"); v != "" {
+		if row.Request.Weight, err = strconv.Atoi(v); err != nil {
+			fields = append(fields, util.FieldError{Field: "weight", Message: "must be a whole number of grams"})
+		}
+	}
+	if v := value("stock"); v != "" {
+		stock, err := strconv.Atoi(v)
+		if err != nil {
+			fields = append(fields, util.FieldError{Field: "stock", Message: "must be a whole number"})
+		}
+		row.Request.Stock = &stock
+	}
+	if v := value("is_discount"); v != "" {
+		if row.Request.IsDiscount, err = strconv.ParseBool(v); err != nil {
+			fields = append(fields, util.FieldError{Field: "is_discount", Message: "must be true or false"})
+		}
+	}
+
+	return row, fields
+}
+
+// parseProductJSONL reads one request.ProductAddRequest per line, blank lines are skipped.
+func parseProductJSONL(body io.Reader) (
+	rows []productImportRow, errs []response.ProductImportRowError, err error,
+) {
+	rows = make([]productImportRow, 0)
+	errs = make([]response.ProductImportRowError, 0)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := productImportRow{Row: line}
+		if err := json.Unmarshal([]byte(text), &row.Request); err != nil {
+			errs = append(errs, response.ProductImportRowError{Row: line, Message: "invalid json: " + err.Error()})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return rows, errs, importReadError("invalid jsonl", err)
+	}
+
+	if len(rows)+len(errs) == 0 {
+		return rows, errs, &util.BadRequestError{Message: "file is empty"}
+	}
+
+	return rows, errs, nil
+}
@@ -0,0 +1,172 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestImportCSVDryRun(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+
+	body := "name,price,description,weight,is_discount,discount_type,discount_value," +
+		"start_date_discount,end_date_discount\n" +
+		"Kopi,25000,Kopi Gayo,250,true,percentage,10,2022-01-01,2022-12-31\n" +
+		"Teh,\"12000.50\",Teh Melati,,,,,,\n"
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": []string{"Kopi", "Teh"}}}}}
+	productMock.EXPECT().Find(gomock.Any(), &b).Return([]entity.Product{{ID: uuid.New(), Name: "Teh"}}, nil)
+
+	productSvc := service.NewProductService(ctx, productMock, nil, nil)
+	req := request.ProductImportRequest{Format: request.ImportFormatCSV, DryRun: true, Upsert: true}
+	res, err := productSvc.Import(ctx, &req, strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, 2, res.Total)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 1, res.Updated)
+	assert.Equal(t, 0, res.Failed)
+}
+
+func TestImportJSONLRowErrors(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+
+	body := `{"name": "Kopi", "price": "25000", "description": "Kopi Gayo"}
+{"name": "Kopi", "price": "26000", "description": "Kopi Toraja"}
+{"name": "Gula", "price": "-1", "description": "Gula Aren"}
+{"name": "Susu", "price": "9000"}
+
+{"name": "Teh", "price": "12000", "description": "Teh Melati"}
+not json`
+
+	productMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.Product{{ID: uuid.New(), Name: "Teh"}}, nil)
+
+	productSvc := service.NewProductService(ctx, productMock, nil, nil)
+	req := request.ProductImportRequest{Format: request.ImportFormatJSONL}
+	res, err := productSvc.Import(ctx, &req, strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, res.Total)
+	assert.Equal(t, 5, res.Failed)
+	assert.Equal(t, 0, res.Created)
+
+	rows := make(map[int]string)
+	for _, e := range res.Errors {
+		rows[e.Row] = e.Message
+	}
+	assert.Equal(t, "invalid json: invalid character 'o' in literal null (expecting 'u')", rows[7])
+	assert.Equal(t, "duplicate of row 1", rows[2])
+	assert.Equal(t, "validation failed", rows[3])
+	assert.Equal(t, "validation failed", rows[4])
+	assert.Equal(t, "product already exists", rows[6])
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	productSvc := service.NewProductService(context.TODO(), nil, nil, nil)
+	req := request.ProductImportRequest{Format: "xml"}
+	_, err := productSvc.Import(context.TODO(), &req, strings.NewReader(""))
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestImportCSVReadErrors(t *testing.T) {
+	ctx := context.TODO()
+	productSvc := service.NewProductService(ctx, nil, nil, nil)
+	req := request.ProductImportRequest{Format: request.ImportFormatCSV}
+
+	body := "name,price,description\n" + strings.Repeat("Kopi,25000,Kopi Gayo\n", 100)
+	tooLarge := http.MaxBytesReader(httptest.NewRecorder(), io.NopCloser(strings.NewReader(body)), 64)
+	_, err := productSvc.Import(ctx, &req, tooLarge)
+	assert.IsType(t, &util.BadRequestError{}, err)
+	assert.Equal(t, "file is larger than 64 bytes", err.Error())
+
+	// the client went away, which isn't a row error
+	_, err = productSvc.Import(ctx, &req, io.MultiReader(strings.NewReader(body), iotest.ErrReader(io.ErrUnexpectedEOF)))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestImportConflictWhileWriting(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+
+	body := "name,price,description\n"
+	for i := 1; i <= 101; i++ {
+		body += fmt.Sprintf("Kopi %d,25000,Kopi Gayo\n", i)
+	}
+
+	productMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productMock).Times(2)
+	productMock.EXPECT().EndTx(gomock.Any()).Times(2)
+	// the last row is created by another request once its name was looked up
+	productMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Product) (entity.Product, error) {
+			if data.Name == "Kopi 101" {
+				return entity.Product{}, &util.ConflictError{Message: "a product with this name was taken"}
+			}
+			return *data, nil
+		},
+	).Times(101)
+
+	productSvc := service.NewProductService(ctx, productMock, nil, nil)
+	req := request.ProductImportRequest{Format: request.ImportFormatCSV}
+	res, err := productSvc.Import(ctx, &req, strings.NewReader(body))
+	assert.IsType(t, &util.ConflictError{}, err)
+	assert.Equal(t, 100, res.Created)
+	assert.Equal(t, 1, res.Failed)
+	if assert.Len(t, res.Errors, 1) {
+		assert.Equal(t, 102, res.Errors[0].Row)
+		assert.Equal(t, "Kopi 101", res.Errors[0].Name)
+	}
+}
+
+func TestImportCSVRowsSpanningLines(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+
+	body := "name,price,description\n" +
+		"Kopi,25000,\"Kopi Gayo\nsangrai sedang\"\n" +
+		"Teh,murah,Teh Melati\n" +
+		"Gula,9000,\"Gula Aren\n\"bad\"\n"
+
+	productMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	productSvc := service.NewProductService(ctx, productMock, nil, nil)
+	req := request.ProductImportRequest{Format: request.ImportFormatCSV}
+	res, err := productSvc.Import(ctx, &req, strings.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Failed)
+
+	rows := make(map[int]string)
+	for _, e := range res.Errors {
+		rows[e.Row] = e.Name
+	}
+	assert.Equal(t, map[int]string{4: "Teh", 5: ""}, rows)
+}
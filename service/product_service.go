@@ -3,40 +3,61 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"interview-telkom-6/entity"
 	"interview-telkom-6/repository/persistence"
 	"interview-telkom-6/request"
 	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
 	"interview-telkom-6/util"
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 )
 
 type ProductService struct {
+	ctx         context.Context
 	productRepo persistence.ProductRepository
+	discountSvc *DiscountService
+	currencySvc *CurrencyService
 }
 
 func NewProductService(
+	ctx context.Context,
 	productRepo persistence.ProductRepository,
+	discountSvc *DiscountService,
+	currencySvc *CurrencyService,
 ) *ProductService {
 	return &ProductService{
+		ctx:         ctx,
 		productRepo: productRepo,
+		discountSvc: discountSvc,
+		currencySvc: currencySvc,
 	}
 }
 
 func (s *ProductService) Find(ctx context.Context, req *request.ProductCriteria) (
 	res *util.PaginationResponse, err error,
 ) {
-	builder := persistence.QueryBuilderCriteria{}
-	builder.Where = &persistence.Where{}
+	ctx, span := tracing.Start(ctx, "ProductService.Find")
+	defer func() { tracing.End(span, err) }()
 
-	if req.Search != "" {
-		and := squirrel.And{squirrel.ILike{"name": "%" + req.Search + "%"}}
-		builder.Where.And = append(builder.Where.And, and)
+	// prices are shown in their own currency unless asked otherwise
+	var conv *CurrencyConverter
+	if req.Currency != "" {
+		conv, err = s.currencySvc.Converter(req.Currency)
+		if err != nil {
+			return res, err
+		}
 	}
 
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = productFilter(req)
+
 	page := uint64(req.Page)
 	limit := uint64(req.Limit)
 	offset := (page - 1) * limit
@@ -47,43 +68,58 @@ func (s *ProductService) Find(ctx context.Context, req *request.ProductCriteria)
 
 	results, err := s.productRepo.Find(ctx, &builder)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
-	for _, val := range results {
+	discounts, err := s.discountSvc.BestDiscounts(ctx, results, time.Now())
+	if err != nil {
+		return res, err
+	}
 
-		data := response.ProductResponse{
-			ID:                val.ID,
-			Name:              val.Name,
-			Price:             val.Price,
-			Description:       val.Description,
-			IsDiscount:        val.IsDiscount,
-			StartDateDiscount: &val.StartDateDiscount.Time,
-			EndDateDiscount:   &val.EndDateDiscount.Time,
-			DiscountValue:     val.DiscountValue.Float64,
+	for _, val := range results {
+		data := buildProductResponse(val, discounts[val.ID])
+		if conv != nil {
+			if err := convertProductResponse(ctx, &data, conv); err != nil {
+				return res, err
+			}
 		}
 
 		responses = append(responses, data)
-
 	}
 
 	totalRow, err := s.productRepo.Count(ctx, &builder)
 	if err != nil {
-		log.Println(err)
 		return res, err
 	}
 
 	return util.BuildPagination(req.Pagination, responses, totalRow), nil
 }
 
+// productFilter returns the conditions of req shared by Find and Export.
+func productFilter(req *request.ProductCriteria) *persistence.Where {
+	where := &persistence.Where{}
+	if req.Search != "" {
+		and := squirrel.And{squirrel.ILike{"name": "%" + req.Search + "%"}}
+		where.And = append(where.And, and)
+	}
+
+	return where
+}
+
 func (s *ProductService) Store(ctx context.Context, req *request.ProductAddRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "ProductService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	productEntity, err := s.validateStore(req)
+	if err != nil {
+		return err
+	}
+
 	// check product
 	productBuilder := persistence.QueryBuilderCriteria{}
 	productBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
 	product, err := s.productRepo.Get(ctx, &productBuilder)
 	if err != sql.ErrNoRows && err != nil {
-		log.Println(err)
 		return err
 	}
 
@@ -92,41 +128,208 @@ func (s *ProductService) Store(ctx context.Context, req *request.ProductAddReque
 	}
 
 	// insert product
-	productEntity := entity.Product{
-		Name:        req.Name,
-		Price:       req.Price,
-		Description: req.Description,
+	_, err = s.productRepo.Store(ctx, &productEntity)
+	if err != nil {
+		return err
 	}
-	if req.IsDiscount {
-		startDD, err := time.Parse("2006-01-02", req.StartDateDiscount)
-		if err != nil {
-			log.Println(err)
-			return err
+
+	return nil
+}
+
+// Update replaces the product id with req. ifMatch, when set, is the version of the product the client read:
+// the update only applies to it and fails with a PreconditionFailedError once the product changed. Without
+// it, a ConflictError tells the product changed between its read here and the update.
+func (s *ProductService) Update(ctx context.Context, id string, req *request.ProductAddRequest, ifMatch *int64) (
+	res response.ProductResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "ProductService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return res, &util.NotFoundError{Message: "product not found"}
+	}
+
+	productEntity, err := s.validateStore(req)
+	if err != nil {
+		return res, err
+	}
+
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+
+	// the version is read from the transaction, the cache may not have the latest one yet
+	productTx := s.productRepo.WithTx(tx)
+	defer productTx.EndTx(ctx)
+	product, err := s.update(ctx, productTx, productID, &productEntity, req.Stock == nil, ifMatch)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return res, err
+	}
+	if err = tx.Commit(); err != nil {
+		return res, err
+	}
+
+	discounts, err := s.discountSvc.BestDiscounts(ctx, []entity.Product{product}, time.Now())
+	if err != nil {
+		return res, err
+	}
+
+	return buildProductResponse(product, discounts[product.ID]), nil
+}
+
+// update replaces the product id with data through productTx, keeping its stock when keepStock is set. See
+// Update for ifMatch.
+func (s *ProductService) update(
+	ctx context.Context, productTx persistence.ProductRepository, id uuid.UUID, data *entity.Product, keepStock bool,
+	ifMatch *int64,
+) (res entity.Product, err error) {
+	productBuilder := persistence.QueryBuilderCriteria{}
+	productBuilder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": id}}}}
+	product, err := productTx.Get(ctx, &productBuilder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, &util.NotFoundError{Message: "product not found"}
 		}
+		return res, err
+	}
 
-		endDD, err := time.Parse("2006-01-02", req.EndDateDiscount)
+	nameBuilder := persistence.QueryBuilderCriteria{}
+	nameBuilder.Where = &persistence.Where{
+		And: []squirrel.And{{squirrel.Eq{"name": data.Name}, squirrel.NotEq{"id": id}}},
+	}
+	_, err = productTx.Get(ctx, &nameBuilder)
+	if err != sql.ErrNoRows {
 		if err != nil {
-			log.Println(err)
-			return err
+			return res, err
 		}
+		return res, &util.BadRequestError{Message: "produk sudah ada"}
+	}
 
-		productEntity.StartDateDiscount = sql.NullTime{
+	if ifMatch != nil && *ifMatch != product.Version {
+		return res, &util.PreconditionFailedError{Message: "the product was changed since it was read"}
+	}
+
+	data.ID, data.Version = product.ID, product.Version
+	if keepStock {
+		data.Stock = product.Stock
+	}
+	res, err = productTx.Update(ctx, data)
+	if err != nil {
+		if ifMatch != nil && errors.Is(err, persistence.ErrProductChanged) {
+			return res, &util.PreconditionFailedError{Message: "the product was changed since it was read"}
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+// validateStore checks the request and turns it into a product, every invalid field is reported at once.
+func (s *ProductService) validateStore(req *request.ProductAddRequest) (res entity.Product, err error) {
+	fields := make([]util.FieldError, 0)
+	if !req.Price.IsPositive() {
+		fields = append(fields, util.FieldError{Field: "price", Message: "must be greater than 0"})
+	}
+
+	if req.Weight < 0 {
+		fields = append(fields, util.FieldError{Field: "weight", Message: "must not be negative"})
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		fields = append(fields, util.FieldError{Field: "stock", Message: "must not be negative"})
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = util.DefaultCurrency
+	}
+	if !util.IsSupportedCurrency(currency) {
+		fields = append(fields, util.FieldError{Field: "currency", Message: "currency isn't supported"})
+	}
+
+	res = entity.Product{
+		Name:        req.Name,
+		Price:       req.Price.RoundTo(currency),
+		Currency:    currency,
+		Description: req.Description,
+		Weight:      req.Weight,
+	}
+	if req.Stock != nil {
+		res.Stock = *req.Stock
+	}
+	if req.Category != "" {
+		res.Category = sql.NullString{String: req.Category, Valid: true}
+	}
+
+	if req.IsDiscount {
+		var startDD, endDD time.Time
+		fields = validateDiscountValue(
+			"discount_type", "discount_value", req.DiscountType, req.DiscountValue, req.Price, fields,
+		)
+		startDD, fields = parseDateField("start_date_discount", req.StartDateDiscount, fields)
+		endDD, fields = parseDateField("end_date_discount", req.EndDateDiscount, fields)
+		fields = validateDateRange("end_date_discount", startDD, endDD, fields)
+
+		res.StartDateDiscount = sql.NullTime{
 			Time:  startDD,
 			Valid: true,
 		}
-		productEntity.EndDateDiscount = sql.NullTime{
+		res.EndDateDiscount = sql.NullTime{
 			Time:  endDD,
 			Valid: true,
 		}
-		productEntity.IsDiscount = req.IsDiscount
-		productEntity.DiscountValue = sql.NullFloat64{Float64: req.DiscountValue, Valid: true}
+		res.IsDiscount = req.IsDiscount
+		res.DiscountType = sql.NullString{String: req.DiscountType, Valid: true}
+		res.DiscountValue = util.NullMoney{Money: req.DiscountValue, Valid: true}
+		if req.DiscountType == entity.DiscountTypeFixed {
+			res.DiscountValue.Money = req.DiscountValue.RoundTo(currency)
+		}
 	}
 
-	_, err = s.productRepo.Store(ctx, &productEntity)
-	if err != nil {
-		log.Println(err)
-		return err
+	return res, util.NewValidationError(fields)
+}
+
+func buildProductResponse(product entity.Product, discount AppliedDiscount) response.ProductResponse {
+	res := response.ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Price:             product.Price.RoundTo(product.Currency),
+		Currency:          product.Currency,
+		Description:       product.Description,
+		Weight:            product.Weight,
+		Stock:             product.Stock,
+		Category:          product.Category.String,
+		IsDiscount:        product.IsDiscount,
+		DiscountType:      product.DiscountType.String,
+		StartDateDiscount: &product.StartDateDiscount.Time,
+		EndDateDiscount:   &product.EndDateDiscount.Time,
+		DiscountValue:     product.DiscountValue.Money,
+		DiscountAmount:    discount.Amount.RoundTo(product.Currency),
+		FinalPrice:        product.PriceAfter(discount.Amount).RoundTo(product.Currency),
+		AppliedDiscounts:  make([]response.AppliedDiscountResponse, 0, len(discount.Lines)),
+		Version:           product.Version,
+	}
+	if res.DiscountType != entity.DiscountTypePercentage {
+		res.DiscountValue = res.DiscountValue.RoundTo(product.Currency)
 	}
 
-	return nil
+	for _, line := range discount.Lines {
+		applied := response.AppliedDiscountResponse{
+			Name:         line.Campaign.Name,
+			DiscountType: line.Campaign.DiscountType,
+			Amount:       line.Amount.RoundTo(product.Currency),
+		}
+		if line.Campaign.ID != uuid.Nil {
+			campaignID := line.Campaign.ID
+			applied.CampaignID = &campaignID
+		}
+
+		res.AppliedDiscounts = append(res.AppliedDiscounts, applied)
+	}
+
+	return res
 }
@@ -13,6 +13,7 @@ import (
 	"interview-telkom-6/repository/persistence/mocks"
 	"interview-telkom-6/request"
 	"interview-telkom-6/service"
+	"interview-telkom-6/util"
 	"testing"
 	"time"
 )
@@ -26,30 +27,31 @@ func TestStore(t *testing.T) {
 	product := entity.Product{
 		// ID:                uuid.New(),
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000).RoundTo(util.DefaultCurrency),
+		Currency:          util.DefaultCurrency,
 		Description:       "Makanan Enak",
 		IsDiscount:        false,
-		DiscountValue:     sql.NullFloat64{},
+		DiscountValue:     util.NullMoney{},
 		StartDateDiscount: sql.NullTime{},
 		EndDateDiscount:   sql.NullTime{},
 	}
 	req := request.ProductAddRequest{
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000),
 		Description:       "Makanan Enak",
 		IsDiscount:        false,
 		StartDateDiscount: "",
 		EndDateDiscount:   "",
-		DiscountValue:     0,
+		DiscountValue:     util.Money{},
 	}
 
 	resProduct := entity.Product{}
 	w := persistence.QueryBuilderCriteria{}
 	w.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
-	productMock.EXPECT().Get(context.TODO(), &w).Return(resProduct, nil)
-	productMock.EXPECT().Store(context.TODO(), &product).Return(product, nil)
+	productMock.EXPECT().Get(gomock.Any(), &w).Return(resProduct, nil)
+	productMock.EXPECT().Store(gomock.Any(), &product).Return(product, nil)
 
-	productSvc := service.NewProductService(productMock)
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	err := productSvc.Store(context.TODO(), &req)
 	assert.NoError(t, err)
@@ -63,12 +65,13 @@ func TestStoreWithDiscount(t *testing.T) {
 
 	req := request.ProductAddRequest{
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000),
 		Description:       "Makanan Enak",
 		IsDiscount:        true,
+		DiscountType:      entity.DiscountTypeFixed,
 		StartDateDiscount: "2022-08-24",
 		EndDateDiscount:   "2022-08-30",
-		DiscountValue:     10000.00,
+		DiscountValue:     util.MoneyFromInt(10000),
 	}
 
 	sdParse, err := time.Parse("2006-01-02", req.StartDateDiscount)
@@ -78,10 +81,12 @@ func TestStoreWithDiscount(t *testing.T) {
 
 	product := entity.Product{
 		Name:              req.Name,
-		Price:             req.Price,
+		Price:             req.Price.RoundTo(util.DefaultCurrency),
+		Currency:          util.DefaultCurrency,
 		Description:       req.Description,
 		IsDiscount:        req.IsDiscount,
-		DiscountValue:     sql.NullFloat64{Float64: req.DiscountValue, Valid: true},
+		DiscountType:      sql.NullString{String: req.DiscountType, Valid: true},
+		DiscountValue:     util.NullMoney{Money: req.DiscountValue.RoundTo(util.DefaultCurrency), Valid: true},
 		StartDateDiscount: sql.NullTime{Time: sdParse, Valid: true},
 		EndDateDiscount:   sql.NullTime{Time: edParse, Valid: true},
 	}
@@ -89,10 +94,10 @@ func TestStoreWithDiscount(t *testing.T) {
 	resProduct := entity.Product{}
 	w := persistence.QueryBuilderCriteria{}
 	w.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
-	productMock.EXPECT().Get(context.TODO(), &w).Return(resProduct, nil)
-	productMock.EXPECT().Store(context.TODO(), &product).Return(product, nil)
+	productMock.EXPECT().Get(gomock.Any(), &w).Return(resProduct, nil)
+	productMock.EXPECT().Store(gomock.Any(), &product).Return(product, nil)
 
-	productSvc := service.NewProductService(productMock)
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	err = productSvc.Store(context.TODO(), &req)
 	assert.NoError(t, err)
@@ -106,12 +111,13 @@ func TestStoreErrorProductExist(t *testing.T) {
 
 	req := request.ProductAddRequest{
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000),
 		Description:       "Makanan Enak",
 		IsDiscount:        true,
+		DiscountType:      entity.DiscountTypeFixed,
 		StartDateDiscount: "2022-08-24",
 		EndDateDiscount:   "2022-08-30",
-		DiscountValue:     10000.00,
+		DiscountValue:     util.MoneyFromInt(10000),
 	}
 
 	sdParse, err := time.Parse("2006-01-02", req.StartDateDiscount)
@@ -121,19 +127,21 @@ func TestStoreErrorProductExist(t *testing.T) {
 
 	product := entity.Product{
 		Name:              req.Name,
-		Price:             req.Price,
+		Price:             req.Price.RoundTo(util.DefaultCurrency),
+		Currency:          util.DefaultCurrency,
 		Description:       req.Description,
 		IsDiscount:        req.IsDiscount,
-		DiscountValue:     sql.NullFloat64{Float64: req.DiscountValue, Valid: true},
+		DiscountType:      sql.NullString{String: req.DiscountType, Valid: true},
+		DiscountValue:     util.NullMoney{Money: req.DiscountValue.RoundTo(util.DefaultCurrency), Valid: true},
 		StartDateDiscount: sql.NullTime{Time: sdParse, Valid: true},
 		EndDateDiscount:   sql.NullTime{Time: edParse, Valid: true},
 	}
 
 	w := persistence.QueryBuilderCriteria{}
 	w.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
-	productMock.EXPECT().Get(context.TODO(), &w).Return(product, nil)
+	productMock.EXPECT().Get(gomock.Any(), &w).Return(product, nil)
 
-	productSvc := service.NewProductService(productMock)
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	err = productSvc.Store(context.TODO(), &req)
 	assert.Error(t, err)
@@ -148,18 +156,19 @@ func TestStoreErrorProductGet(t *testing.T) {
 
 	req := request.ProductAddRequest{
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000),
 		Description:       "Makanan Enak",
 		IsDiscount:        true,
+		DiscountType:      entity.DiscountTypeFixed,
 		StartDateDiscount: "2022-08-24",
 		EndDateDiscount:   "2022-08-30",
-		DiscountValue:     10000.00,
+		DiscountValue:     util.MoneyFromInt(10000),
 	}
 	w := persistence.QueryBuilderCriteria{}
 	w.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
-	productMock.EXPECT().Get(context.TODO(), &w).Return(entity.Product{}, errors.New("something wrong"))
+	productMock.EXPECT().Get(gomock.Any(), &w).Return(entity.Product{}, errors.New("something wrong"))
 
-	productSvc := service.NewProductService(productMock)
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	err := productSvc.Store(context.TODO(), &req)
 	assert.Error(t, err)
@@ -173,12 +182,13 @@ func TestStoreError(t *testing.T) {
 
 	req := request.ProductAddRequest{
 		Name:              "Makanan",
-		Price:             10000,
+		Price:             util.MoneyFromInt(10000),
 		Description:       "Makanan Enak",
 		IsDiscount:        true,
+		DiscountType:      entity.DiscountTypeFixed,
 		StartDateDiscount: "2022-08-24",
 		EndDateDiscount:   "2022-08-30",
-		DiscountValue:     10000.00,
+		DiscountValue:     util.MoneyFromInt(10000),
 	}
 
 	sdParse, err := time.Parse("2006-01-02", req.StartDateDiscount)
@@ -188,10 +198,12 @@ func TestStoreError(t *testing.T) {
 
 	product := entity.Product{
 		Name:              req.Name,
-		Price:             req.Price,
+		Price:             req.Price.RoundTo(util.DefaultCurrency),
+		Currency:          util.DefaultCurrency,
 		Description:       req.Description,
 		IsDiscount:        req.IsDiscount,
-		DiscountValue:     sql.NullFloat64{Float64: req.DiscountValue, Valid: true},
+		DiscountType:      sql.NullString{String: req.DiscountType, Valid: true},
+		DiscountValue:     util.NullMoney{Money: req.DiscountValue.RoundTo(util.DefaultCurrency), Valid: true},
 		StartDateDiscount: sql.NullTime{Time: sdParse, Valid: true},
 		EndDateDiscount:   sql.NullTime{Time: edParse, Valid: true},
 	}
@@ -199,21 +211,79 @@ func TestStoreError(t *testing.T) {
 	resProduct := entity.Product{}
 	w := persistence.QueryBuilderCriteria{}
 	w.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"name": req.Name}}}}
-	productMock.EXPECT().Get(context.TODO(), &w).Return(resProduct, nil)
-	productMock.EXPECT().Store(context.TODO(), &product).Return(product, errors.New("something wrong"))
+	productMock.EXPECT().Get(gomock.Any(), &w).Return(resProduct, nil)
+	productMock.EXPECT().Store(gomock.Any(), &product).Return(product, errors.New("something wrong"))
 
-	productSvc := service.NewProductService(productMock)
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	err = productSvc.Store(context.TODO(), &req)
 	assert.Error(t, err)
 }
 
+func TestStoreErrorInvalidDiscount(t *testing.T) {
+	mockCrtl := gomock.NewController(t)
+	defer mockCrtl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCrtl)
+
+	req := request.ProductAddRequest{
+		Name:              "Makanan",
+		Price:             util.MoneyFromInt(10000),
+		Description:       "Makanan Enak",
+		IsDiscount:        true,
+		DiscountType:      entity.DiscountTypeFixed,
+		StartDateDiscount: "2022-08-30",
+		EndDateDiscount:   "2022-08-24",
+		DiscountValue:     util.MoneyFromInt(15000),
+	}
+
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
+
+	err := productSvc.Store(context.TODO(), &req)
+	badRequest, ok := err.(*util.BadRequestError)
+	assert.True(t, ok)
+	assert.ElementsMatch(
+		t, []util.FieldError{
+			{Field: "discount_value", Message: "can't be greater than price 10000.00"},
+			{Field: "end_date_discount", Message: "can't be before the start date"},
+		}, badRequest.Fields,
+	)
+}
+
+func TestStoreErrorInvalidDiscountType(t *testing.T) {
+	mockCrtl := gomock.NewController(t)
+	defer mockCrtl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCrtl)
+
+	req := request.ProductAddRequest{
+		Name:              "Makanan",
+		Price:             util.MoneyFromInt(10000),
+		Description:       "Makanan Enak",
+		IsDiscount:        true,
+		StartDateDiscount: "24-08-2022",
+		EndDateDiscount:   "2022-08-30",
+		DiscountValue:     util.MoneyFromInt(10),
+	}
+
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
+
+	err := productSvc.Store(context.TODO(), &req)
+	badRequest, ok := err.(*util.BadRequestError)
+	assert.True(t, ok)
+	assert.Len(t, badRequest.Fields, 2)
+	assert.Equal(t, "discount_type", badRequest.Fields[0].Field)
+	assert.Equal(t, "start_date_discount", badRequest.Fields[1].Field)
+}
+
 func TestFind(t *testing.T) {
 	ctx := context.TODO()
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	productMock := mocks.NewMockProductRepository(mockCtrl)
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
 
 	req := request.ProductCriteria{}
 	req.Limit = 1
@@ -231,19 +301,21 @@ func TestFind(t *testing.T) {
 		{
 			ID:                uuid.New(),
 			Name:              "Makanan",
-			Price:             10000,
+			Price:             util.MoneyFromInt(10000),
 			Description:       "Makanan Enak",
 			IsDiscount:        false,
 			StartDateDiscount: sql.NullTime{},
 			EndDateDiscount:   sql.NullTime{},
-			DiscountValue:     sql.NullFloat64{},
+			DiscountValue:     util.NullMoney{},
 		},
 	}
 	var totalRow int64 = 1
-	productMock.EXPECT().Find(ctx, &b).Return(res, nil)
-	productMock.EXPECT().Count(ctx, &b).Return(totalRow, nil)
+	productMock.EXPECT().Find(gomock.Any(), &b).Return(res, nil)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+	productMock.EXPECT().Count(gomock.Any(), &b).Return(totalRow, nil)
 
-	productSvc := service.NewProductService(productMock)
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	productSvc := service.NewProductService(context.TODO(), productMock, discountSvc, nil)
 
 	results, err := productSvc.Find(ctx, &req)
 	assert.NoError(t, err)
@@ -257,6 +329,8 @@ func TestFindWithSearch(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	productMock := mocks.NewMockProductRepository(mockCtrl)
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
 
 	req := request.ProductCriteria{}
 	req.Search = "makanan"
@@ -275,19 +349,21 @@ func TestFindWithSearch(t *testing.T) {
 		{
 			ID:                uuid.New(),
 			Name:              "Makanan",
-			Price:             10000,
+			Price:             util.MoneyFromInt(10000),
 			Description:       "Makanan Enak",
 			IsDiscount:        false,
 			StartDateDiscount: sql.NullTime{},
 			EndDateDiscount:   sql.NullTime{},
-			DiscountValue:     sql.NullFloat64{},
+			DiscountValue:     util.NullMoney{},
 		},
 	}
 	var totalRow int64 = 1
-	productMock.EXPECT().Find(ctx, &b).Return(res, nil)
-	productMock.EXPECT().Count(ctx, &b).Return(totalRow, nil)
+	productMock.EXPECT().Find(gomock.Any(), &b).Return(res, nil)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+	productMock.EXPECT().Count(gomock.Any(), &b).Return(totalRow, nil)
 
-	productSvc := service.NewProductService(productMock)
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	productSvc := service.NewProductService(context.TODO(), productMock, discountSvc, nil)
 
 	results, err := productSvc.Find(ctx, &req)
 	assert.NoError(t, err)
@@ -318,16 +394,16 @@ func TestFindError(t *testing.T) {
 		{
 			ID:                uuid.New(),
 			Name:              "Makanan",
-			Price:             10000,
+			Price:             util.MoneyFromInt(10000),
 			Description:       "Makanan Enak",
 			IsDiscount:        false,
 			StartDateDiscount: sql.NullTime{},
 			EndDateDiscount:   sql.NullTime{},
-			DiscountValue:     sql.NullFloat64{},
+			DiscountValue:     util.NullMoney{},
 		},
 	}
-	productMock.EXPECT().Find(ctx, &b).Return(res, errors.New("something wrong"))
-	productSvc := service.NewProductService(productMock)
+	productMock.EXPECT().Find(gomock.Any(), &b).Return(res, errors.New("something wrong"))
+	productSvc := service.NewProductService(context.TODO(), productMock, nil, nil)
 
 	_, err := productSvc.Find(ctx, &req)
 	assert.Error(t, err)
@@ -339,6 +415,8 @@ func TestFindCountError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	productMock := mocks.NewMockProductRepository(mockCtrl)
+	campaignMock := mocks.NewMockDiscountCampaignRepository(mockCtrl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCtrl)
 
 	req := request.ProductCriteria{}
 	req.Limit = 1
@@ -356,19 +434,133 @@ func TestFindCountError(t *testing.T) {
 		{
 			ID:                uuid.New(),
 			Name:              "Makanan",
-			Price:             10000,
+			Price:             util.MoneyFromInt(10000),
 			Description:       "Makanan Enak",
 			IsDiscount:        false,
 			StartDateDiscount: sql.NullTime{},
 			EndDateDiscount:   sql.NullTime{},
-			DiscountValue:     sql.NullFloat64{},
+			DiscountValue:     util.NullMoney{},
 		},
 	}
 	var totalRow int64 = 0
-	productMock.EXPECT().Find(ctx, &b).Return(res, nil)
-	productMock.EXPECT().Count(ctx, &b).Return(totalRow, errors.New("something wrong"))
-	productSvc := service.NewProductService(productMock)
+	productMock.EXPECT().Find(gomock.Any(), &b).Return(res, nil)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+	productMock.EXPECT().Count(gomock.Any(), &b).Return(totalRow, errors.New("something wrong"))
+	discountSvc := service.NewDiscountService(ctx, campaignMock, campaignTargetMock, nil)
+	productSvc := service.NewProductService(context.TODO(), productMock, discountSvc, nil)
 
 	_, err := productSvc.Find(ctx, &req)
 	assert.Error(t, err)
 }
+
+func TestUpdate(t *testing.T) {
+	mockCrtl := gomock.NewController(t)
+	defer mockCrtl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCrtl)
+	productTxMock := mocks.NewMockProductRepository(mockCrtl)
+	campaignTargetMock := mocks.NewMockDiscountCampaignTargetRepository(mockCrtl)
+
+	current := entity.Product{
+		ID:          uuid.New(),
+		Name:        "Makanan",
+		Price:       util.MoneyFromInt(10000),
+		Currency:    util.DefaultCurrency,
+		Description: "Makanan Enak",
+		Version:     3,
+	}
+	req := request.ProductAddRequest{Name: "Makanan", Price: util.MoneyFromInt(12000), Description: "Makanan Enak"}
+
+	// the product is read from the transaction, not from the cache another instance may have made outdated
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productTxMock)
+	productTxMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(current, nil)
+	productTxMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.Product{}, sql.ErrNoRows)
+	productTxMock.EXPECT().EndTx(gomock.Any())
+	productTxMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Product) (entity.Product, error) {
+			assert.Equal(t, int64(3), data.Version)
+			assert.Equal(t, current.ID, data.ID)
+			assert.Equal(t, "12000.00", data.Price.String())
+			data.Version++
+			return *data, nil
+		},
+	)
+	campaignTargetMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.DiscountCampaignTarget{}, nil)
+
+	discountSvc := service.NewDiscountService(context.TODO(), nil, campaignTargetMock, nil)
+	productSvc := service.NewProductService(txContext(), productMock, discountSvc, nil)
+
+	ifMatch := int64(3)
+	res, err := productSvc.Update(context.TODO(), current.ID.String(), &req, &ifMatch)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), res.Version)
+	assert.Equal(t, "12000.00", res.FinalPrice.String())
+}
+
+func TestUpdateConflict(t *testing.T) {
+	mockCrtl := gomock.NewController(t)
+	defer mockCrtl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCrtl)
+	current := entity.Product{ID: uuid.New(), Name: "Makanan", Price: util.MoneyFromInt(10000), Version: 2}
+	req := request.ProductAddRequest{Name: "Makanan", Price: util.MoneyFromInt(12000), Description: "Makanan Enak"}
+
+	productMock.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, builder *persistence.QueryBuilderCriteria) (entity.Product, error) {
+			if _, ok := builder.Where.And[0][0].(squirrel.Eq)["id"]; ok {
+				return current, nil
+			}
+			return entity.Product{}, sql.ErrNoRows
+		},
+	).Times(8)
+	productMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(
+		entity.Product{}, persistence.ErrProductChanged,
+	).Times(2)
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productMock).Times(4)
+	productMock.EXPECT().EndTx(gomock.Any()).Times(4)
+
+	productSvc := service.NewProductService(txContext(), productMock, nil, nil)
+
+	ifMatch := int64(2)
+	_, err := productSvc.Update(context.TODO(), current.ID.String(), &req, &ifMatch)
+	assert.IsType(t, &util.PreconditionFailedError{}, err)
+
+	_, err = productSvc.Update(context.TODO(), current.ID.String(), &req, nil)
+	assert.IsType(t, &util.ConflictError{}, err)
+
+	// a version older than the one read fails without trying the update
+	ifMatch = 1
+	_, err = productSvc.Update(context.TODO(), current.ID.String(), &req, &ifMatch)
+	assert.IsType(t, &util.PreconditionFailedError{}, err)
+
+	// a name taken in between by another product isn't a version mismatch
+	ifMatch = 2
+	productMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(
+		entity.Product{}, &util.ConflictError{Message: "a product with this name was created by another request"},
+	)
+	_, err = productSvc.Update(context.TODO(), current.ID.String(), &req, &ifMatch)
+	assert.IsType(t, &util.ConflictError{}, err)
+}
+
+func TestUpdateErrors(t *testing.T) {
+	mockCrtl := gomock.NewController(t)
+	defer mockCrtl.Finish()
+
+	productMock := mocks.NewMockProductRepository(mockCrtl)
+	req := request.ProductAddRequest{Name: "Minuman", Price: util.MoneyFromInt(12000), Description: "Segar"}
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productMock).Times(2)
+	productMock.EXPECT().EndTx(gomock.Any()).Times(2)
+	productSvc := service.NewProductService(txContext(), productMock, nil, nil)
+
+	_, err := productSvc.Update(context.TODO(), "not-an-id", &req, nil)
+	assert.IsType(t, &util.NotFoundError{}, err)
+
+	productMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.Product{}, sql.ErrNoRows)
+	_, err = productSvc.Update(context.TODO(), uuid.NewString(), &req, nil)
+	assert.IsType(t, &util.NotFoundError{}, err)
+
+	// another product already has the name
+	productMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(entity.Product{ID: uuid.New()}, nil).Times(2)
+	_, err = productSvc.Update(context.TODO(), uuid.NewString(), &req, nil)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
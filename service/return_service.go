@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+	"log/slog"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ReturnService takes items of paid payments back. A return is requested, approved, received, which puts
+// its items back in stock, then refunded what was paid for them through PaymentService.
+type ReturnService struct {
+	ctx         context.Context
+	paymentRepo persistence.PaymentRepository
+	returnRepo  persistence.ReturnRepository
+	productRepo persistence.ProductRepository
+	paymentSvc  *PaymentService
+}
+
+func NewReturnService(
+	ctx context.Context, paymentRepo persistence.PaymentRepository, returnRepo persistence.ReturnRepository,
+	productRepo persistence.ProductRepository, paymentSvc *PaymentService,
+) *ReturnService {
+	return &ReturnService{
+		ctx: ctx, paymentRepo: paymentRepo, returnRepo: returnRepo, productRepo: productRepo, paymentSvc: paymentSvc,
+	}
+}
+
+// Store requests the return of req.Items of the payment paymentID. Items can't be returned twice, returns
+// other than rejected ones counting, the payment row is locked meanwhile so that concurrent requests can't.
+func (s *ReturnService) Store(ctx context.Context, paymentID string, req *request.ReturnAddRequest) (
+	res response.ReturnResponse, err error,
+) {
+	ctx, span := tracing.Start(ctx, "ReturnService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	id, err := uuid.Parse(paymentID)
+	if err != nil {
+		return res, &util.NotFoundError{Message: "payment not found"}
+	}
+
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+
+	data, err := s.store(ctx, tx, id, req)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return res, err
+	}
+	if err = tx.Commit(); err != nil {
+		return res, err
+	}
+
+	return buildReturnResponse(data), nil
+}
+
+func (s *ReturnService) store(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, req *request.ReturnAddRequest) (
+	res entity.Return, err error,
+) {
+	payment, err := s.paymentRepo.WithTx(tx).GetForUpdate(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, &util.NotFoundError{Message: "payment not found"}
+		}
+		return res, err
+	}
+
+	if payment.Status != entity.PaymentStatusPaid {
+		return res, &util.BadRequestError{Message: "only items of paid payments can be returned"}
+	}
+	if payment.Snapshot == nil {
+		return res, &util.BadRequestError{Message: "the items of the payment are unknown"}
+	}
+
+	returnTx := s.returnRepo.WithTx(tx)
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"payment_id": payment.ID}}, {squirrel.NotEq{"status": entity.ReturnStatusRejected}},
+		},
+	}
+	returns, err := returnTx.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	returned := make(map[uuid.UUID]int)
+	for _, r := range returns {
+		for _, item := range r.Items {
+			returned[item.ProductID] += item.Quantity
+		}
+	}
+
+	items := itemQuantities(req.Items)
+	if err := util.NewValidationError(validateItems(payment.Snapshot, items, returned)); err != nil {
+		return res, err
+	}
+
+	res = entity.Return{
+		PaymentID: payment.ID,
+		Status:    entity.ReturnStatusRequested,
+		Reason:    req.Reason,
+		Items:     items,
+		CreatedAt: time.Now(),
+	}
+	res.UpdatedAt = res.CreatedAt
+
+	return returnTx.Store(ctx, &res)
+}
+
+// Approve accepts the requested return id, its items can be sent back.
+func (s *ReturnService) Approve(ctx context.Context, id string) (res response.ReturnResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReturnService.Approve")
+	defer func() { tracing.End(span, err) }()
+
+	data, err := s.get(ctx, s.returnRepo, id)
+	if err != nil {
+		return res, err
+	}
+
+	if err = s.move(ctx, s.returnRepo, &data, entity.ReturnStatusApproved, entity.ReturnStatusRequested); err != nil {
+		return res, err
+	}
+
+	return buildReturnResponse(data), nil
+}
+
+// Reject refuses the return id, which must not have been received yet. Its items can be returned again.
+func (s *ReturnService) Reject(ctx context.Context, id string) (res response.ReturnResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReturnService.Reject")
+	defer func() { tracing.End(span, err) }()
+
+	data, err := s.get(ctx, s.returnRepo, id)
+	if err != nil {
+		return res, err
+	}
+
+	err = s.move(
+		ctx, s.returnRepo, &data, entity.ReturnStatusRejected, entity.ReturnStatusRequested,
+		entity.ReturnStatusApproved,
+	)
+	if err != nil {
+		return res, err
+	}
+
+	return buildReturnResponse(data), nil
+}
+
+// Receive records that the items of the approved return id came back, and puts them back in stock.
+// Products deleted since are skipped.
+func (s *ReturnService) Receive(ctx context.Context, id string) (res response.ReturnResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReturnService.Receive")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := s.ctx.Value("db").(*sqlx.DB).BeginTxx(ctx, nil)
+	if err != nil {
+		return res, err
+	}
+
+	productTx := s.productRepo.WithTx(tx)
+	defer productTx.EndTx(ctx)
+
+	data, err := s.receive(ctx, tx, productTx, id)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			slog.WarnContext(ctx, "rollback failed", "error", err)
+		}
+		return res, err
+	}
+	if err = tx.Commit(); err != nil {
+		return res, err
+	}
+
+	return buildReturnResponse(data), nil
+}
+
+func (s *ReturnService) receive(
+	ctx context.Context, tx *sqlx.Tx, productTx persistence.ProductRepository, id string,
+) (res entity.Return, err error) {
+	returnTx := s.returnRepo.WithTx(tx)
+	res, err = s.get(ctx, returnTx, id)
+	if err != nil {
+		return res, err
+	}
+
+	if err = s.move(ctx, returnTx, &res, entity.ReturnStatusReceived, entity.ReturnStatusApproved); err != nil {
+		return res, err
+	}
+
+	for _, item := range res.Items {
+		restocked, err := productTx.AddStock(ctx, item.ProductID, item.Quantity)
+		if err != nil {
+			return res, err
+		}
+		if !restocked {
+			slog.WarnContext(ctx, "returned product not found", "return_id", res.ID, "product_id", item.ProductID)
+		}
+	}
+
+	return res, nil
+}
+
+// Refund gives back what was paid for the items of the received return id, then moves it to refunded.
+func (s *ReturnService) Refund(ctx context.Context, id string) (res response.RefundResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReturnService.Refund")
+	defer func() { tracing.End(span, err) }()
+
+	data, err := s.get(ctx, s.returnRepo, id)
+	if err != nil {
+		return res, err
+	}
+	if data.Status != entity.ReturnStatusReceived {
+		return res, &util.BadRequestError{Message: "only received returns can be refunded"}
+	}
+
+	res, err = s.paymentSvc.refund(
+		ctx, data.PaymentID, entity.Refund{
+			Reason: data.Reason, Items: data.Items, ReturnID: uuid.NullUUID{UUID: data.ID, Valid: true},
+		},
+	)
+	if err != nil {
+		return res, err
+	}
+
+	// the refund is made, a return left received can't be refunded twice all the same
+	err = s.move(context.WithoutCancel(ctx), s.returnRepo, &data, entity.ReturnStatusRefunded, data.Status)
+	if err != nil {
+		slog.WarnContext(ctx, "return left received", "return_id", data.ID, "error", err)
+	}
+
+	return res, nil
+}
+
+func (s *ReturnService) get(ctx context.Context, repo persistence.ReturnRepository, id string) (
+	res entity.Return, err error,
+) {
+	returnID, err := uuid.Parse(id)
+	if err != nil {
+		return res, &util.NotFoundError{Message: "return not found"}
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{And: []squirrel.And{{squirrel.Eq{"id": returnID}}}}
+	res, err = repo.Get(ctx, &builder)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return res, &util.NotFoundError{Message: "return not found"}
+		}
+		return res, err
+	}
+
+	return res, nil
+}
+
+// move changes the status of data to status when it is one of from, a ConflictError telling another request
+// changed it in between.
+func (s *ReturnService) move(
+	ctx context.Context, repo persistence.ReturnRepository, data *entity.Return, status string, from ...string,
+) error {
+	current := data.Status
+	allowed := false
+	for _, f := range from {
+		allowed = allowed || f == current
+	}
+	if !allowed {
+		return &util.BadRequestError{Message: "the return is " + current}
+	}
+
+	data.Status = status
+	data.UpdatedAt = time.Now()
+	updated, err := repo.UpdateStatus(ctx, data, current)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return &util.ConflictError{Message: "the return was changed by another request"}
+	}
+
+	return nil
+}
+
+func buildReturnResponse(data entity.Return) response.ReturnResponse {
+	return response.ReturnResponse{
+		ID:        data.ID,
+		PaymentID: data.PaymentID,
+		Status:    data.Status,
+		Reason:    data.Reason,
+		Items:     itemQuantityResponses(data.Items),
+		CreatedAt: data.CreatedAt,
+		UpdatedAt: data.UpdatedAt,
+	}
+}
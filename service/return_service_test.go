@@ -0,0 +1,242 @@
+package service_test
+
+import (
+	"context"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestStoreReturn(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+
+	coffee := uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), Amount: util.MoneyFromInt(900), Currency: "IDR", Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{
+			Currency: "IDR",
+			Items:    []entity.PaymentItem{{ProductID: coffee, Quantity: 3, FinalPrice: util.MoneyFromInt(300)}},
+		},
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		And: []squirrel.And{
+			{squirrel.Eq{"payment_id": payment.ID}}, {squirrel.NotEq{"status": entity.ReturnStatusRejected}},
+		},
+	}
+	returnMock.EXPECT().WithTx(gomock.Any()).Return(returnMock)
+	returnMock.EXPECT().Find(gomock.Any(), &b).Return(
+		[]entity.Return{{Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 1}}}}, nil,
+	)
+	returnMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Return) (entity.Return, error) {
+			assert.Equal(t, entity.ReturnStatusRequested, data.Status)
+			data.GenerateUUID()
+			return *data, nil
+		},
+	)
+
+	returnSvc := service.NewReturnService(ctx, paymentMock, returnMock, nil, nil)
+	req := request.ReturnAddRequest{
+		Reason: "damaged", Items: []request.ItemQuantityRequest{{ProductID: coffee, Quantity: 2}},
+	}
+	res, err := returnSvc.Store(ctx, payment.ID.String(), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ReturnStatusRequested, res.Status)
+	assert.Equal(t, 2, res.Items[0].Quantity)
+}
+
+func TestStoreReturnTooMany(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+
+	coffee := uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{Items: []entity.PaymentItem{{ProductID: coffee, Quantity: 3}}},
+	}
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	returnMock.EXPECT().WithTx(gomock.Any()).Return(returnMock)
+	returnMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.Return{{Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 2}}}}, nil,
+	)
+
+	returnSvc := service.NewReturnService(ctx, paymentMock, returnMock, nil, nil)
+	req := request.ReturnAddRequest{
+		Reason: "damaged", Items: []request.ItemQuantityRequest{{ProductID: coffee, Quantity: 2}},
+	}
+	_, err := returnSvc.Store(ctx, payment.ID.String(), &req)
+	var badRequest *util.BadRequestError
+	if assert.ErrorAs(t, err, &badRequest) {
+		assert.Equal(
+			t, []util.FieldError{{Field: "items[0].quantity", Message: "must be between 1 and 1"}}, badRequest.Fields,
+		)
+	}
+}
+
+func TestReceiveReturnRestocks(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+
+	coffee, tea := uuid.New(), uuid.New()
+	data := entity.Return{
+		ID: uuid.New(), Status: entity.ReturnStatusApproved,
+		Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 2}, {ProductID: tea, Quantity: 1}},
+	}
+	returnMock.EXPECT().WithTx(gomock.Any()).Return(returnMock)
+	returnMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(data, nil)
+	returnMock.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), entity.ReturnStatusApproved).DoAndReturn(
+		func(ctx context.Context, data *entity.Return, from string) (bool, error) {
+			assert.Equal(t, entity.ReturnStatusReceived, data.Status)
+			return true, nil
+		},
+	)
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productMock)
+	productMock.EXPECT().EndTx(gomock.Any())
+	productMock.EXPECT().AddStock(gomock.Any(), coffee, 2).Return(true, nil)
+	// deleted since, the return is received all the same
+	productMock.EXPECT().AddStock(gomock.Any(), tea, 1).Return(false, nil)
+
+	returnSvc := service.NewReturnService(ctx, nil, returnMock, productMock, nil)
+	res, err := returnSvc.Receive(ctx, data.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ReturnStatusReceived, res.Status)
+}
+
+func TestReceiveReturnNotApproved(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+	productMock := mocks.NewMockProductRepository(mockCtrl)
+	data := entity.Return{ID: uuid.New(), Status: entity.ReturnStatusRequested}
+	returnMock.EXPECT().WithTx(gomock.Any()).Return(returnMock)
+	returnMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(data, nil)
+	productMock.EXPECT().WithTx(gomock.Any()).Return(productMock)
+	productMock.EXPECT().EndTx(gomock.Any())
+
+	returnSvc := service.NewReturnService(ctx, nil, returnMock, productMock, nil)
+	_, err := returnSvc.Receive(ctx, data.ID.String())
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestRefundReturn(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+
+	coffee := uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), ProviderRef: "ch_1", Amount: util.MoneyFromInt(600), Currency: "IDR",
+		Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{
+			Currency: "IDR",
+			Items:    []entity.PaymentItem{{ProductID: coffee, Quantity: 2, FinalPrice: util.MoneyFromInt(300)}},
+			SubTotal: util.MoneyFromInt(600),
+		},
+	}
+	data := entity.Return{
+		ID: uuid.New(), PaymentID: payment.ID, Status: entity.ReturnStatusReceived, Reason: "damaged",
+		Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 1}},
+	}
+	returnMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(data, nil)
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.Refund{}, nil)
+	refundMock.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	refundMock.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, data *entity.Refund) (entity.Refund, error) {
+			return *data, nil
+		},
+	)
+	returnMock.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), entity.ReturnStatusReceived).DoAndReturn(
+		func(ctx context.Context, data *entity.Return, from string) (bool, error) {
+			assert.Equal(t, entity.ReturnStatusRefunded, data.Status)
+			return true, nil
+		},
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	returnSvc := service.NewReturnService(ctx, paymentMock, returnMock, nil, paymentSvc)
+	res, err := returnSvc.Refund(ctx, data.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "300.00", res.Amount.String())
+	if assert.NotNil(t, res.ReturnID) {
+		assert.Equal(t, data.ID, *res.ReturnID)
+	}
+}
+
+func TestRefundReturnTwice(t *testing.T) {
+	ctx := txContext()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	paymentMock := mocks.NewMockPaymentRepository(mockCtrl)
+	refundMock := mocks.NewMockRefundRepository(mockCtrl)
+	returnMock := mocks.NewMockReturnRepository(mockCtrl)
+
+	coffee := uuid.New()
+	payment := entity.Payment{
+		ID: uuid.New(), Amount: util.MoneyFromInt(600), Currency: "IDR", Status: entity.PaymentStatusPaid,
+		Snapshot: &entity.PaymentSnapshot{
+			Items: []entity.PaymentItem{{ProductID: coffee, Quantity: 2, FinalPrice: util.MoneyFromInt(300)}},
+		},
+	}
+	data := entity.Return{
+		ID: uuid.New(), PaymentID: payment.ID, Status: entity.ReturnStatusReceived,
+		Items: entity.ItemQuantities{{ProductID: coffee, Quantity: 1}},
+	}
+	returnMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(data, nil)
+	paymentMock.EXPECT().WithTx(gomock.Any()).Return(paymentMock)
+	paymentMock.EXPECT().GetForUpdate(gomock.Any(), payment.ID).Return(payment, nil)
+	refundMock.EXPECT().WithTx(gomock.Any()).Return(refundMock)
+	refundMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(
+		[]entity.Refund{
+			{Amount: util.MoneyFromInt(300), Items: data.Items, ReturnID: uuid.NullUUID{UUID: data.ID, Valid: true}},
+		}, nil,
+	)
+
+	paymentSvc := service.NewPaymentService(
+		ctx, paymentMock, refundMock, nil, nil, service.NewFakePaymentProvider("secret"),
+	)
+	returnSvc := service.NewReturnService(ctx, paymentMock, returnMock, nil, paymentSvc)
+	_, err := returnSvc.Refund(ctx, data.ID.String())
+	assert.IsType(t, &util.ConflictError{}, err)
+}
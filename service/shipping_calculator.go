@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/util"
+	"strings"
+)
+
+// ShippingCalculator quotes the shipping services able to deliver a parcel. It is the extension point for
+// carrier integrations, WeightZoneCalculator is the built-in table based one.
+type ShippingCalculator interface {
+	Quote(ctx context.Context, parcel Parcel) (res []ShippingOption, err error)
+}
+
+// Parcel is what has to be shipped, Weight is in grams.
+type Parcel struct {
+	Destination entity.Address
+	Weight      int
+}
+
+type ShippingOption struct {
+	Service       string
+	Name          string
+	Zone          string
+	Cost          util.Money
+	Currency      string
+	EstimatedDays int
+}
+
+// ShippingZone groups destinations sharing the same rates, either some Provinces of Country or whole
+// Countries. A zone listing neither matches any address.
+type ShippingZone struct {
+	Name      string
+	Country   string
+	Provinces []string
+	Countries []string
+}
+
+// ShippingRate is the cost of a service within a zone: FirstKg for the first kilogram started, NextKg for
+// every other kilogram started.
+type ShippingRate struct {
+	Zone          string
+	Service       string
+	Name          string
+	FirstKg       util.Money
+	NextKg        util.Money
+	EstimatedDays int
+}
+
+// WeightZoneCalculator prices a parcel from a table of rates per zone and weight, all rates are in Currency.
+// Zones are matched in order, the first matching one is used.
+type WeightZoneCalculator struct {
+	Currency string
+	Zones    []ShippingZone
+	Rates    []ShippingRate
+}
+
+// NewDefaultShippingCalculator returns the rate table we ship with from Jakarta.
+func NewDefaultShippingCalculator() *WeightZoneCalculator {
+	return &WeightZoneCalculator{
+		Currency: util.DefaultCurrency,
+		Zones: []ShippingZone{
+			{Name: "jabodetabek", Country: "ID", Provinces: []string{"DKI Jakarta", "Jawa Barat", "Banten"}},
+			{Name: "java", Country: "ID", Provinces: []string{"Jawa Tengah", "DI Yogyakarta", "Jawa Timur"}},
+			{Name: "domestic", Countries: []string{"ID"}},
+			{Name: "international"},
+		},
+		Rates: []ShippingRate{
+			{
+				Zone: "jabodetabek", Service: "regular", Name: "Regular", FirstKg: util.MoneyFromInt(10000),
+				NextKg: util.MoneyFromInt(5000), EstimatedDays: 2,
+			},
+			{
+				Zone: "jabodetabek", Service: "express", Name: "Express", FirstKg: util.MoneyFromInt(18000),
+				NextKg: util.MoneyFromInt(9000), EstimatedDays: 1,
+			},
+			{
+				Zone: "java", Service: "regular", Name: "Regular", FirstKg: util.MoneyFromInt(15000),
+				NextKg: util.MoneyFromInt(8000), EstimatedDays: 3,
+			},
+			{
+				Zone: "java", Service: "express", Name: "Express", FirstKg: util.MoneyFromInt(25000),
+				NextKg: util.MoneyFromInt(13000), EstimatedDays: 2,
+			},
+			{
+				Zone: "domestic", Service: "regular", Name: "Regular", FirstKg: util.MoneyFromInt(30000),
+				NextKg: util.MoneyFromInt(18000), EstimatedDays: 5,
+			},
+			{
+				Zone: "domestic", Service: "express", Name: "Express", FirstKg: util.MoneyFromInt(45000),
+				NextKg: util.MoneyFromInt(28000), EstimatedDays: 3,
+			},
+			{
+				Zone: "international", Service: "international", Name: "International", FirstKg: util.MoneyFromInt(250000),
+				NextKg: util.MoneyFromInt(150000), EstimatedDays: 10,
+			},
+		},
+	}
+}
+
+// Quote returns every rate of the zone of the destination, in the order of the table. Parcels are billed
+// per kilogram started and never less than one kilogram.
+func (c *WeightZoneCalculator) Quote(ctx context.Context, parcel Parcel) (res []ShippingOption, err error) {
+	zone, ok := c.zone(parcel.Destination)
+	if !ok {
+		return res, &util.BadRequestError{Message: "no shipping service to this address"}
+	}
+
+	kg := int64((parcel.Weight + 999) / 1000)
+	if kg < 1 {
+		kg = 1
+	}
+
+	res = make([]ShippingOption, 0)
+	for _, rate := range c.Rates {
+		if rate.Zone != zone {
+			continue
+		}
+
+		res = append(
+			res, ShippingOption{
+				Service:       rate.Service,
+				Name:          rate.Name,
+				Zone:          zone,
+				Cost:          rate.FirstKg.Add(rate.NextKg.MulInt(kg - 1)),
+				Currency:      c.Currency,
+				EstimatedDays: rate.EstimatedDays,
+			},
+		)
+	}
+
+	if len(res) == 0 {
+		return res, &util.BadRequestError{Message: "no shipping service to this address"}
+	}
+
+	return res, nil
+}
+
+func (c *WeightZoneCalculator) zone(address entity.Address) (string, bool) {
+	for _, z := range c.Zones {
+		if len(z.Provinces) > 0 {
+			if strings.EqualFold(z.Country, address.Country) && containsFold(z.Provinces, address.Province) {
+				return z.Name, true
+			}
+			continue
+		}
+
+		if len(z.Countries) == 0 || containsFold(z.Countries, address.Country) {
+			return z.Name, true
+		}
+	}
+
+	return "", false
+}
+
+func containsFold(values []string, value string) bool {
+	value = strings.TrimSpace(value)
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
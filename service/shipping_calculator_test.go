@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestQuoteShipping(t *testing.T) {
+	calc := service.NewDefaultShippingCalculator()
+
+	tests := []struct {
+		name    string
+		address entity.Address
+		weight  int
+		zone    string
+		cost    string
+	}{
+		{"under a kilogram", entity.Address{Province: "Banten", Country: "ID"}, 200, "jabodetabek", "10000.00"},
+		{"kilogram started", entity.Address{Province: "jawa timur", Country: "id"}, 1001, "java", "23000.00"},
+		{"without weight", entity.Address{Province: "Bali", Country: "ID"}, 0, "domestic", "30000.00"},
+		{"abroad", entity.Address{Province: "Banten", Country: "SG"}, 2000, "international", "400000.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				res, err := calc.Quote(context.TODO(), service.Parcel{Destination: tt.address, Weight: tt.weight})
+				assert.NoError(t, err)
+				assert.Equal(t, tt.zone, res[0].Zone)
+				assert.Equal(t, tt.cost, res[0].Cost.String())
+				assert.Equal(t, util.DefaultCurrency, res[0].Currency)
+			},
+		)
+	}
+}
+
+func TestQuoteShippingWithoutZone(t *testing.T) {
+	calc := &service.WeightZoneCalculator{
+		Currency: util.DefaultCurrency,
+		Zones:    []service.ShippingZone{{Name: "domestic", Countries: []string{"ID"}}},
+	}
+
+	_, err := calc.Quote(context.TODO(), service.Parcel{Destination: entity.Address{Country: "SG"}, Weight: 100})
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/request"
+	"interview-telkom-6/response"
+	"interview-telkom-6/tracing"
+	"interview-telkom-6/util"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+type TaxService struct {
+	taxRuleRepo persistence.TaxRuleRepository
+}
+
+// TaxableLine is an amount to be taxed, already discounted and converted to the currency of the breakdown.
+type TaxableLine struct {
+	Category string
+	Amount   util.Money
+}
+
+// TaxBreakdown holds the tax due per rule. Exclusive is the part of Total that isn't already in the prices
+// and has to be added on top of them.
+type TaxBreakdown struct {
+	Taxes     []response.TaxResponse
+	Total     util.Money
+	Exclusive util.Money
+}
+
+func NewTaxService(taxRuleRepo persistence.TaxRuleRepository) *TaxService {
+	return &TaxService{taxRuleRepo: taxRuleRepo}
+}
+
+func (s *TaxService) Find(ctx context.Context) (res []response.TaxRuleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxService.Find")
+	defer func() { tracing.End(span, err) }()
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Order = map[string]string{"name": "ASC"}
+
+	results, err := s.taxRuleRepo.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	res = make([]response.TaxRuleResponse, 0, len(results))
+	for _, val := range results {
+		res = append(
+			res, response.TaxRuleResponse{
+				ID:          val.ID,
+				Name:        val.Name,
+				Category:    val.Category.String,
+				Rate:        val.Rate,
+				IsInclusive: val.IsInclusive,
+			},
+		)
+	}
+
+	return res, nil
+}
+
+func (s *TaxService) Store(ctx context.Context, req *request.TaxRuleAddRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "TaxService.Store")
+	defer func() { tracing.End(span, err) }()
+
+	if req.Rate.IsNegative() || req.Rate.GreaterThan(util.MoneyFromInt(100)) {
+		return util.NewValidationError(
+			[]util.FieldError{{Field: "rate", Message: "must be between 0 and 100 percent"}},
+		)
+	}
+
+	rule := entity.TaxRule{
+		Name:        req.Name,
+		Rate:        req.Rate,
+		IsInclusive: req.IsInclusive,
+	}
+	if req.Category != "" {
+		rule.Category = sql.NullString{String: req.Category, Valid: true}
+	}
+
+	// only one rule per category, and one default rule, which the keys of the table enforce against
+	// concurrent requests
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{Eq: []squirrel.Eq{{"category": nil}}}
+	if rule.Category.Valid {
+		builder.Where = &persistence.Where{Eq: []squirrel.Eq{{"category": rule.Category.String}}}
+	}
+	_, err = s.taxRuleRepo.Get(ctx, &builder)
+	if err != sql.ErrNoRows && err != nil {
+		return err
+	}
+
+	if err == nil {
+		return &util.BadRequestError{Message: "tax rule for this category already exists"}
+	}
+
+	_, err = s.taxRuleRepo.Store(ctx, &rule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Breakdown computes the tax of lines in currency, loading only the rules lines may need.
+// See TaxRules.Breakdown.
+func (s *TaxService) Breakdown(ctx context.Context, lines []TaxableLine, currency string) (res TaxBreakdown, err error) {
+	ctx, span := tracing.Start(ctx, "TaxService.Breakdown")
+	defer func() { tracing.End(span, err) }()
+
+	if len(lines) == 0 {
+		return TaxRules{}.Breakdown(lines, currency), nil
+	}
+
+	categories := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.Category != "" {
+			categories = append(categories, line.Category)
+		}
+	}
+
+	builder := persistence.QueryBuilderCriteria{}
+	builder.Where = &persistence.Where{
+		Or: []squirrel.Or{{squirrel.Eq{"category": categories}, squirrel.Eq{"category": nil}}},
+	}
+	rules, err := s.taxRuleRepo.Find(ctx, &builder)
+	if err != nil {
+		return res, err
+	}
+
+	return NewTaxRules(rules).Breakdown(lines, currency), nil
+}
+
+// Rules loads every tax rule, for callers computing the tax of many carts at once.
+func (s *TaxService) Rules(ctx context.Context) (res TaxRules, err error) {
+	ctx, span := tracing.Start(ctx, "TaxService.Rules")
+	defer func() { tracing.End(span, err) }()
+
+	rules, err := s.taxRuleRepo.Find(ctx, &persistence.QueryBuilderCriteria{})
+	if err != nil {
+		return res, err
+	}
+
+	return NewTaxRules(rules), nil
+}
+
+// TaxRules are tax rules indexed by category.
+type TaxRules struct {
+	defaultRule *entity.TaxRule
+	byCategory  map[string]entity.TaxRule
+}
+
+func NewTaxRules(rules []entity.TaxRule) TaxRules {
+	res := TaxRules{byCategory: make(map[string]entity.TaxRule)}
+	for i, rule := range rules {
+		if !rule.Category.Valid {
+			res.defaultRule = &rules[i]
+			continue
+		}
+		res.byCategory[rule.Category.String] = rule
+	}
+
+	return res
+}
+
+// Breakdown computes the tax of lines in currency. Each line is taxed by the rule of its category, or the
+// default rule when there is none, lines without any rule are not taxed.
+//
+// Amounts are summed per rule first and the tax of each rule is rounded once to the minor unit of currency,
+// so the breakdown adds up to the total and doesn't drift with the number of lines. The tax included in
+// a price is price * rate / (100 + rate), the tax added on top of a price is price * rate / 100.
+func (r TaxRules) Breakdown(lines []TaxableLine, currency string) (res TaxBreakdown) {
+	res.Taxes = make([]response.TaxResponse, 0)
+
+	// keep the rules in the order they are first met so the breakdown is stable
+	order := make([]uuid.UUID, 0)
+	amounts := make(map[uuid.UUID]util.Money)
+	applied := make(map[uuid.UUID]entity.TaxRule)
+	for _, line := range lines {
+		rule, ok := r.byCategory[line.Category]
+		if !ok {
+			if r.defaultRule == nil {
+				continue
+			}
+			rule = *r.defaultRule
+		}
+
+		if _, ok := amounts[rule.ID]; !ok {
+			order = append(order, rule.ID)
+			applied[rule.ID] = rule
+		}
+		amounts[rule.ID] = amounts[rule.ID].Add(line.Amount)
+	}
+
+	hundred := util.MoneyFromInt(100)
+	for _, id := range order {
+		rule := applied[id]
+		tax := response.TaxResponse{
+			Name:        rule.Name,
+			Rate:        rule.Rate,
+			IsInclusive: rule.IsInclusive,
+		}
+
+		if rule.IsInclusive {
+			tax.Amount = amounts[id].Ratio(rule.Rate, hundred.Add(rule.Rate)).RoundTo(currency)
+			tax.TaxableAmount = amounts[id].Sub(tax.Amount)
+		} else {
+			tax.Amount = amounts[id].Ratio(rule.Rate, hundred).RoundTo(currency)
+			tax.TaxableAmount = amounts[id]
+			res.Exclusive = res.Exclusive.Add(tax.Amount)
+		}
+
+		res.Total = res.Total.Add(tax.Amount)
+		res.Taxes = append(res.Taxes, tax)
+	}
+
+	return res
+}
@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/Masterminds/squirrel"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/repository/persistence"
+	"interview-telkom-6/repository/persistence/mocks"
+	"interview-telkom-6/request"
+	"interview-telkom-6/service"
+	"interview-telkom-6/util"
+	"testing"
+)
+
+func TestTaxBreakdown(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	taxRuleMock := mocks.NewMockTaxRuleRepository(mockCtrl)
+
+	ppn := entity.TaxRule{ID: uuid.New(), Name: "PPN", Rate: util.MoneyFromInt(11)}
+	food := entity.TaxRule{
+		ID: uuid.New(), Name: "PB1", Category: sql.NullString{String: "food", Valid: true},
+		Rate: util.MoneyFromInt(10), IsInclusive: true,
+	}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{
+		Or: []squirrel.Or{{squirrel.Eq{"category": []string{"food", "food"}}, squirrel.Eq{"category": nil}}},
+	}
+	taxRuleMock.EXPECT().Find(gomock.Any(), &b).Return([]entity.TaxRule{ppn, food}, nil)
+
+	lines := []service.TaxableLine{
+		{Category: "food", Amount: util.MoneyFromInt(11000)},
+		{Category: "", Amount: util.MustMoney("1000.50")},
+		{Category: "food", Amount: util.MoneyFromInt(5500)},
+	}
+
+	taxSvc := service.NewTaxService(taxRuleMock)
+	res, err := taxSvc.Breakdown(ctx, lines, util.DefaultCurrency)
+	assert.NoError(t, err)
+	assert.Len(t, res.Taxes, 2)
+
+	// 16500 already includes 10%: 16500 * 10 / 110
+	assert.Equal(t, "PB1", res.Taxes[0].Name)
+	assert.Equal(t, "1500.00", res.Taxes[0].Amount.String())
+	assert.Equal(t, "15000.00", res.Taxes[0].TaxableAmount.String())
+
+	// 1000.50 * 11% = 110.055, added on top of the price
+	assert.Equal(t, "PPN", res.Taxes[1].Name)
+	assert.Equal(t, "110.06", res.Taxes[1].Amount.String())
+	assert.Equal(t, "1000.50", res.Taxes[1].TaxableAmount.String())
+
+	assert.Equal(t, "1610.06", res.Total.String())
+	assert.Equal(t, "110.06", res.Exclusive.String())
+}
+
+func TestTaxBreakdownWithoutRule(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	taxRuleMock := mocks.NewMockTaxRuleRepository(mockCtrl)
+	taxRuleMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return([]entity.TaxRule{}, nil)
+
+	taxSvc := service.NewTaxService(taxRuleMock)
+	res, err := taxSvc.Breakdown(ctx, []service.TaxableLine{{Amount: util.MoneyFromInt(1000)}}, "IDR")
+	assert.NoError(t, err)
+	assert.Len(t, res.Taxes, 0)
+	assert.True(t, res.Total.IsZero())
+}
+
+func TestTaxBreakdownError(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	taxRuleMock := mocks.NewMockTaxRuleRepository(mockCtrl)
+	taxRuleMock.EXPECT().Find(gomock.Any(), gomock.Any()).Return(nil, errors.New("something wrong"))
+
+	taxSvc := service.NewTaxService(taxRuleMock)
+	_, err := taxSvc.Breakdown(ctx, []service.TaxableLine{{Amount: util.MoneyFromInt(1000)}}, "IDR")
+	assert.Error(t, err)
+}
+
+func TestStoreTaxRule(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	taxRuleMock := mocks.NewMockTaxRuleRepository(mockCtrl)
+
+	req := request.TaxRuleAddRequest{Name: "PB1", Category: "food", Rate: util.MoneyFromInt(10), IsInclusive: true}
+	rule := entity.TaxRule{
+		Name:        req.Name,
+		Category:    sql.NullString{String: req.Category, Valid: true},
+		Rate:        req.Rate,
+		IsInclusive: req.IsInclusive,
+	}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{Eq: []squirrel.Eq{{"category": "food"}}}
+	taxRuleMock.EXPECT().Get(gomock.Any(), &b).Return(entity.TaxRule{}, sql.ErrNoRows)
+	taxRuleMock.EXPECT().Store(gomock.Any(), &rule).Return(rule, nil)
+
+	taxSvc := service.NewTaxService(taxRuleMock)
+	err := taxSvc.Store(ctx, &req)
+	assert.NoError(t, err)
+}
+
+func TestStoreTaxRuleExist(t *testing.T) {
+	ctx := context.TODO()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	taxRuleMock := mocks.NewMockTaxRuleRepository(mockCtrl)
+
+	req := request.TaxRuleAddRequest{Name: "PPN", Rate: util.MoneyFromInt(11)}
+
+	b := persistence.QueryBuilderCriteria{}
+	b.Where = &persistence.Where{Eq: []squirrel.Eq{{"category": nil}}}
+	taxRuleMock.EXPECT().Get(gomock.Any(), &b).Return(entity.TaxRule{ID: uuid.New()}, nil)
+
+	taxSvc := service.NewTaxService(taxRuleMock)
+	err := taxSvc.Store(ctx, &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
+
+func TestStoreTaxRuleInvalidRate(t *testing.T) {
+	taxSvc := service.NewTaxService(nil)
+
+	req := request.TaxRuleAddRequest{Name: "PPN", Rate: util.MoneyFromInt(110)}
+	err := taxSvc.Store(context.TODO(), &req)
+	assert.IsType(t, &util.BadRequestError{}, err)
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// noopDriver opens connections whose transactions do nothing, for services beginning transactions over
+// mocked repositories. Queries fail, the repositories are expected to be mocked.
+type noopDriver struct{}
+
+func (noopDriver) Open(string) (driver.Conn, error) {
+	return noopConn{}, nil
+}
+
+type noopConn struct{}
+
+func (noopConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("noop driver: queries aren't supported")
+}
+
+func (noopConn) Close() error {
+	return nil
+}
+
+func (noopConn) Begin() (driver.Tx, error) {
+	return noopTx{}, nil
+}
+
+type noopTx struct{}
+
+func (noopTx) Commit() error {
+	return nil
+}
+
+func (noopTx) Rollback() error {
+	return nil
+}
+
+// txContext returns a context holding, under "db", a database whose transactions do nothing.
+func txContext() context.Context {
+	return context.WithValue(context.TODO(), "db", sqlx.NewDb(sql.OpenDB(noopConnector{}), "noop"))
+}
+
+type noopConnector struct{}
+
+func (noopConnector) Connect(context.Context) (driver.Conn, error) {
+	return noopConn{}, nil
+}
+
+func (noopConnector) Driver() driver.Driver {
+	return noopDriver{}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"interview-telkom-6/entity"
+	"interview-telkom-6/util"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDateField parses a YYYY-MM-DD request field, recording a field error when it can't.
+func parseDateField(field, value string, fields []util.FieldError) (time.Time, []util.FieldError) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return date, append(fields, util.FieldError{Field: field, Message: "must be a date with format YYYY-MM-DD"})
+	}
+
+	return date, fields
+}
+
+// validateDateRange records a field error on endField when the end date comes before the start date.
+func validateDateRange(endField string, start, end time.Time, fields []util.FieldError) []util.FieldError {
+	if start.IsZero() || end.IsZero() || !end.Before(start) {
+		return fields
+	}
+
+	return append(fields, util.FieldError{Field: endField, Message: "can't be before the start date"})
+}
+
+// validateDiscountValue checks the discount type and its value. A fixed amount is only bounded when price is
+// greater than zero, campaigns don't know the price of the products they will be applied to.
+func validateDiscountValue(
+	typeField, valueField, discountType string, value, price util.Money, fields []util.FieldError,
+) []util.FieldError {
+	switch discountType {
+	case entity.DiscountTypePercentage:
+		if !value.IsPositive() || value.GreaterThan(util.MoneyFromInt(100)) {
+			fields = append(fields, util.FieldError{Field: valueField, Message: "must be between 0 and 100 percent"})
+		}
+	case entity.DiscountTypeFixed:
+		if !value.IsPositive() {
+			fields = append(fields, util.FieldError{Field: valueField, Message: "must be greater than 0"})
+		}
+		if price.IsPositive() && value.GreaterThan(price) {
+			fields = append(
+				fields, util.FieldError{Field: valueField, Message: fmt.Sprintf("can't be greater than price %s", price)},
+			)
+		}
+	default:
+		fields = append(
+			fields, util.FieldError{
+				Field:   typeField,
+				Message: fmt.Sprintf("must be %s or %s", entity.DiscountTypePercentage, entity.DiscountTypeFixed),
+			},
+		)
+	}
+
+	return fields
+}
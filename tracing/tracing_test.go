@@ -0,0 +1,47 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"interview-telkom-6/config"
+	"interview-telkom-6/tracing"
+	"testing"
+)
+
+func TestStartEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctx, parent := tracing.Start(context.TODO(), "CartService.Find")
+	_, child := tracing.Start(ctx, "carts.Get")
+	tracing.End(child, errors.New("boom"))
+	tracing.End(parent, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "carts.Get", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := tracing.Setup(context.TODO(), config.TracingConfig{Exporter: "none"})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.TODO()))
+
+	shutdown, err = tracing.Setup(
+		context.TODO(), config.TracingConfig{Exporter: "stdout", ServiceName: "shop", SampleRatio: 1},
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.TODO()))
+
+	_, err = tracing.Setup(context.TODO(), config.TracingConfig{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency is the currency prices are stored in when none is given, and carts are totalled in.
+const DefaultCurrency = "IDR"
+
+// RatePlaces is the number of decimal places exchange rates are stored with.
+const RatePlaces = 10
+
+// currencyPlaces holds the ISO 4217 minor units of the currencies we sell in.
+var currencyPlaces = map[string]int32{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+}
+
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyPlaces[currency]
+	return ok
+}
+
+// MaxMoneyPlaces is the most minor units a supported currency has, the scale of the amount columns.
+const MaxMoneyPlaces = 3
+
+// RoundTo rounds half away from zero to the minor unit of currency, e.g. whole yen for JPY, which the result
+// is then formatted with. Unknown currencies are rounded to MoneyPlaces.
+func (m Money) RoundTo(currency string) Money {
+	return Money{d: m.d, currency: currency}.Round()
+}
+
+// Currency returns the currency m was rounded to, empty when it wasn't.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// places returns the minor units of the currency of m, MoneyPlaces when it has none or an unknown one.
+func (m Money) places() int32 {
+	if places, ok := currencyPlaces[m.currency]; ok {
+		return places
+	}
+
+	return MoneyPlaces
+}
+
+// Convert multiplies m by rate and rounds the result to the minor unit of currency.
+func (m Money) Convert(rate Rate, currency string) Money {
+	return Money{d: m.d.Mul(rate.d)}.RoundTo(currency)
+}
+
+// Rate is an exchange rate, kept apart from Money since it needs more decimal places than any currency.
+type Rate struct {
+	d decimal.Decimal
+}
+
+func RateFromString(value string) (Rate, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{d: d}, nil
+}
+
+// Inverse returns 1 / r rounded to RatePlaces, used to convert the opposite way of a stored rate.
+func (r Rate) Inverse() Rate {
+	return Rate{d: decimal.NewFromInt(1).DivRound(r.d, RatePlaces)}
+}
+
+func (r Rate) IsPositive() bool {
+	return r.d.IsPositive()
+}
+
+func (r Rate) String() string {
+	return r.d.String()
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	return r.d.UnmarshalJSON(data)
+}
+
+func (r *Rate) Scan(value interface{}) error {
+	return r.d.Scan(value)
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.d.Round(RatePlaces).String(), nil
+}
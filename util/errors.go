@@ -1,6 +1,8 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -14,13 +16,29 @@ func (n *NotFoundError) Error() string {
 }
 
 type BadRequestError struct {
-	Message string `json:"message"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields"`
 }
 
 func (n *BadRequestError) Error() string {
 	return n.Message
 }
 
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// NewValidationError returns nil when fields is empty, so callers can collect field errors and return the result as is.
+func NewValidationError(fields []FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &BadRequestError{Message: "validation failed", Fields: fields}
+}
+
 type UnauthorizedError struct {
 	Message string `json:"message"`
 }
@@ -29,8 +47,50 @@ func (n *UnauthorizedError) Error() string {
 	return n.Message
 }
 
+// ConflictError is a request clashing with the current state of a resource, which may succeed later.
+type ConflictError struct {
+	Message string `json:"message"`
+}
+
+func (n *ConflictError) Error() string {
+	return n.Message
+}
+
+// UnprocessableEntityError is a well-formed request that can't be served as it stands, retrying it won't help.
+type UnprocessableEntityError struct {
+	Message string `json:"message"`
+}
+
+func (n *UnprocessableEntityError) Error() string {
+	return n.Message
+}
+
+// PreconditionFailedError is a request whose If-Match header doesn't match the current version of the
+// resource, the client has to read it again before changing it.
+type PreconditionFailedError struct {
+	Message string `json:"message"`
+}
+
+func (n *PreconditionFailedError) Error() string {
+	return n.Message
+}
+
+// BuildErrorAPI writes the response matching err and records err on c for the request log. A query that ran
+// past its timeout, wrapping context.DeadlineExceeded, gets a 504.
 func BuildErrorAPI(c *gin.Context, err error) {
-	switch err.(type) {
+	_ = c.Error(err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.AbortWithStatusJSON(
+			http.StatusGatewayTimeout, map[string]interface{}{
+				"message": "StatusGatewayTimeout",
+				"status":  "failed",
+				"error":   "the request took too long",
+			},
+		)
+		return
+	}
+
+	switch e := err.(type) {
 	case *NotFoundError:
 		c.AbortWithStatusJSON(
 			http.StatusNotFound, map[string]interface{}{
@@ -41,18 +101,48 @@ func BuildErrorAPI(c *gin.Context, err error) {
 		)
 		return
 	case *BadRequestError:
+		body := map[string]interface{}{
+			"message": "StatusBadRequest",
+			"status":  "failed",
+			"error":   err.Error(),
+		}
+		if len(e.Fields) > 0 {
+			body["fields"] = e.Fields
+		}
+
+		c.AbortWithStatusJSON(http.StatusBadRequest, body)
+		return
+	case *UnauthorizedError:
 		c.AbortWithStatusJSON(
-			http.StatusBadRequest, map[string]interface{}{
-				"message": "StatusBadRequest",
+			http.StatusUnauthorized, map[string]interface{}{
+				"message": "StatusUnauthorized",
 				"status":  "failed",
 				"error":   err.Error(),
 			},
 		)
 		return
-	case *UnauthorizedError:
+	case *ConflictError:
 		c.AbortWithStatusJSON(
-			http.StatusUnauthorized, map[string]interface{}{
-				"message": "StatusUnauthorized",
+			http.StatusConflict, map[string]interface{}{
+				"message": "StatusConflict",
+				"status":  "failed",
+				"error":   err.Error(),
+			},
+		)
+		return
+	case *PreconditionFailedError:
+		c.AbortWithStatusJSON(
+			http.StatusPreconditionFailed, map[string]interface{}{
+				"message": "StatusPreconditionFailed",
+				"status":  "failed",
+				"error":   err.Error(),
+			},
+		)
+		return
+	case *UnprocessableEntityError:
+		c.AbortWithStatusJSON(
+			http.StatusUnprocessableEntity, map[string]interface{}{
+				"message": "StatusUnprocessableEntity",
 				"status":  "failed",
 				"error":   err.Error(),
 			},
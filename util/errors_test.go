@@ -0,0 +1,40 @@
+package util_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"interview-telkom-6/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildErrorAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{&util.NotFoundError{Message: "cart not found"}, http.StatusNotFound},
+		{util.NewValidationError([]util.FieldError{{Field: "name", Message: "is required"}}), http.StatusBadRequest},
+		{&util.UnauthorizedError{Message: "invalid signature"}, http.StatusUnauthorized},
+		{&util.ConflictError{Message: "request in progress"}, http.StatusConflict},
+		{&util.UnprocessableEntityError{Message: "key reused"}, http.StatusUnprocessableEntity},
+		{&util.PreconditionFailedError{Message: "product changed"}, http.StatusPreconditionFailed},
+		{fmt.Errorf("%w: pq: canceling statement due to user request", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, val := range cases {
+		res := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(res)
+
+		util.BuildErrorAPI(c, val.err)
+		assert.Equal(t, val.status, res.Code, val.err.Error())
+		assert.Len(t, c.Errors, 1)
+	}
+}
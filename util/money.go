@@ -0,0 +1,199 @@
+package util
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// MoneyPlaces is the number of decimal places of amounts in no particular currency.
+const MoneyPlaces = 2
+
+// ratioPlaces bounds the precision of divisions, well beyond any rounding applied afterwards.
+const ratioPlaces = 16
+
+// Money is an exact decimal amount, used for prices, discounts and totals instead of float64.
+//
+// Arithmetic on Money is exact, nothing is rounded until Round or RoundTo is called. Rounding is always half
+// away from zero, the same rule Postgres applies when writing into a numeric column, so an amount reads back
+// exactly as it was sent. Percentages are rounded once per unit amount (see Percent), totals are then summed
+// from the rounded unit amounts so every line adds up to the total shown.
+//
+// An amount rounded with RoundTo keeps its currency, which sets the decimal places it is rounded, formatted
+// and stored with, e.g. "1000" for JPY and "1.250" for KWD. Sums and products of it keep the currency too.
+// Other amounts use MoneyPlaces. Money is encoded to JSON as such a string, e.g. "10000.00", and decoded from
+// either a string or a JSON number.
+type Money struct {
+	d decimal.Decimal
+	// currency is the one m was rounded to, empty when it wasn't.
+	currency string
+}
+
+func MoneyFromInt(value int64) Money {
+	return Money{d: decimal.NewFromInt(value)}
+}
+
+func MoneyFromString(value string) (Money, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{d: d}, nil
+}
+
+// MustMoney is MoneyFromString for constant amounts, it panics when value isn't a number.
+func MustMoney(value string) Money {
+	m, err := MoneyFromString(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func (m Money) Add(other Money) Money {
+	return m.with(m.d.Add(other.d), other)
+}
+
+func (m Money) Sub(other Money) Money {
+	return m.with(m.d.Sub(other.d), other)
+}
+
+func (m Money) MulInt(n int64) Money {
+	return Money{d: m.d.Mul(decimal.NewFromInt(n)), currency: m.currency}
+}
+
+// Percent returns percent percent of m, rounded.
+func (m Money) Percent(percent Money) Money {
+	return Money{d: m.d.Mul(percent.d).Div(decimal.NewFromInt(100)), currency: m.currency}.Round()
+}
+
+// Ratio returns m * numerator / denominator without rounding, e.g. the tax included in a price is
+// price.Ratio(rate, 100 + rate).
+func (m Money) Ratio(numerator, denominator Money) Money {
+	return Money{d: m.d.Mul(numerator.d).DivRound(denominator.d, ratioPlaces), currency: m.currency}
+}
+
+// Round rounds half away from zero to the decimal places of m.
+func (m Money) Round() Money {
+	return Money{d: m.d.Round(m.places()), currency: m.currency}
+}
+
+// with returns d in the currency of m, or of other when m has none.
+func (m Money) with(d decimal.Decimal, other Money) Money {
+	currency := m.currency
+	if currency == "" {
+		currency = other.currency
+	}
+
+	return Money{d: d, currency: currency}
+}
+
+func (m Money) Cmp(other Money) int {
+	return m.d.Cmp(other.d)
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.d.Equal(other.d)
+}
+
+func (m Money) GreaterThan(other Money) bool {
+	return m.d.GreaterThan(other.d)
+}
+
+func (m Money) LessThan(other Money) bool {
+	return m.d.LessThan(other.d)
+}
+
+func (m Money) IsZero() bool {
+	return m.d.IsZero()
+}
+
+func (m Money) IsPositive() bool {
+	return m.d.IsPositive()
+}
+
+func (m Money) IsNegative() bool {
+	return m.d.IsNegative()
+}
+
+// Min returns the smaller of m and other.
+func (m Money) Min(other Money) Money {
+	if other.LessThan(m) {
+		return other
+	}
+
+	return m
+}
+
+// String formats m rounded to its decimal places.
+func (m Money) String() string {
+	return m.d.StringFixed(m.places())
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	return m.d.UnmarshalJSON(data)
+}
+
+func (m *Money) Scan(value interface{}) error {
+	return m.d.Scan(value)
+}
+
+// Value stores m rounded to its currency, an amount in no currency is stored as it is, the column rounding it
+// to its own scale. This way an amount read from the database in a currency with more decimal places than
+// MoneyPlaces is written back unchanged.
+func (m Money) Value() (driver.Value, error) {
+	if m.currency == "" {
+		return m.d.String(), nil
+	}
+
+	return m.String(), nil
+}
+
+// NullMoney is a Money that may be null, like sql.NullFloat64.
+type NullMoney struct {
+	Money Money
+	Valid bool
+}
+
+func (n NullMoney) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return n.Money.MarshalJSON()
+}
+
+func (n *NullMoney) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.Money, n.Valid = Money{}, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.Money.UnmarshalJSON(data)
+}
+
+func (n *NullMoney) Scan(value interface{}) error {
+	if value == nil {
+		n.Money, n.Valid = Money{}, false
+		return nil
+	}
+
+	n.Valid = true
+	return n.Money.Scan(value)
+}
+
+func (n NullMoney) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.Money.Value()
+}
@@ -0,0 +1,70 @@
+package util_test
+
+import (
+	"encoding/json"
+	"interview-telkom-6/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyJSON(t *testing.T) {
+	var req struct {
+		Price    util.Money `json:"price"`
+		Discount util.Money `json:"discount"`
+	}
+
+	err := json.Unmarshal([]byte(`{"price": 19999.99, "discount": "0.1"}`), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "20000.09", req.Price.Add(req.Discount).String())
+
+	b, err := json.Marshal(req)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price": "19999.99", "discount": "0.10"}`, string(b))
+}
+
+func TestMoneyPercentRounding(t *testing.T) {
+	price := util.MustMoney("10.05")
+
+	// 10.05 * 15% = 1.5075, rounded half away from zero
+	assert.Equal(t, "1.51", price.Percent(util.MoneyFromInt(15)).String())
+	assert.Equal(t, "0.01", util.MustMoney("0.005").Round().String())
+	assert.Equal(t, "-0.01", util.MustMoney("-0.005").Round().String())
+}
+
+func TestMoneyCurrencyPlaces(t *testing.T) {
+	price := util.MustMoney("1.2345").RoundTo("KWD")
+	assert.Equal(t, "1.235", price.String())
+	assert.Equal(t, "2.470", price.MulInt(2).String())
+	assert.Equal(t, "1.235", util.MustMoney("0").Add(price).String())
+	assert.Equal(t, "0.185", price.Percent(util.MoneyFromInt(15)).String())
+
+	value, err := price.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.235", value)
+
+	b, err := json.Marshal(util.MustMoney("141.47").RoundTo("JPY"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"141"`, string(b))
+
+	// read back from a numeric(21,3) column, stored again as it is
+	var stored util.Money
+	assert.NoError(t, stored.Scan("1.235"))
+	value, err = stored.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.235", value)
+}
+
+func TestNullMoneyJSON(t *testing.T) {
+	var n util.NullMoney
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &n))
+	assert.False(t, n.Valid)
+
+	b, err := json.Marshal(n)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", string(b))
+
+	assert.NoError(t, json.Unmarshal([]byte(`"12.5"`), &n))
+	assert.True(t, n.Valid)
+	assert.Equal(t, "12.50", n.Money.String())
+}
@@ -2,7 +2,6 @@ package util
 
 import (
 	"encoding/base64"
-	"log"
 	"net/http"
 	"strings"
 )
@@ -10,7 +9,6 @@ import (
 func GetFileName(filename, data string) (string, []byte, error) {
 	base64Decode, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
-		log.Println(err)
 		return "", nil, err
 	}
 	mimeType := http.DetectContentType(base64Decode)
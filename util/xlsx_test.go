@@ -0,0 +1,56 @@
+package util_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"interview-telkom-6/util"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	xw, err := util.NewXLSXWriter(&buf, "Products")
+	assert.NoError(t, err)
+	assert.NoError(t, xw.WriteRow("name", "price", "weight", "is_discount"))
+	assert.NoError(t, xw.WriteRow("Kopi <Gayo> & Teh", util.MustMoney("25000.5"), 250, true))
+	assert.NoError(t, xw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `name="Products"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<t xml:space="preserve">Kopi &lt;Gayo&gt; &amp; Teh</t>`)
+	assert.Contains(t, sheet, `<c><v>25000.50</v></c><c><v>250</v></c><c t="b"><v>1</v></c>`)
+
+	// every part must be well formed
+	for name, content := range parts {
+		dec := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err, name) {
+				break
+			}
+		}
+	}
+}